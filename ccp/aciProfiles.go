@@ -17,8 +17,6 @@ package ccp
 
 import (
 	"encoding/json"
-	"fmt"
-	"net/http"
 )
 
 type ACIProfile struct {
@@ -26,7 +24,7 @@ type ACIProfile struct {
 	Name                     *string                    `json:"name,omitempty" `
 	APICHosts                *string                    `json:"apic_hosts,omitempty"`
 	APICUsername             *int64                     `json:"apic_username,omitempty"`
-	APICPassword             *int64                     `json:"apic_password,omitempty"`
+	APICPassword             *int64                     `json:"apic_password,omitempty" sensitive:"true"`
 	ACIVMMDomainName         *string                    `json:"aci_vmm_domain_namestate,omitempty"`
 	ACIInfraVLANID           *string                    `json:"aci_infra_vlan_id,omitempty" `
 	VRFName                  *string                    `json:"vrf_name,omitempty"`
@@ -48,9 +46,7 @@ type ACIProfileAllocatorConfig struct {
 
 func (s *Client) GetACIProfiles() ([]ACIProfile, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/aci_profiles")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/aci_profiles"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,327 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// How a change to a cluster field can be made, reported in FieldChange.Type
+const (
+	// ChangePatch fields can be changed on the existing cluster with PatchCluster
+	ChangePatch = "patch"
+	// ChangeRecreate fields can only be changed by deleting the cluster and creating it again
+	ChangeRecreate = "recreate"
+	// ChangeImmutable fields are set by CCP and cannot be changed
+	ChangeImmutable = "immutable"
+)
+
+// Actions of a ClusterPlan
+const (
+	ActionNone   = "none"
+	ActionCreate = "create"
+	ActionPatch  = "patch"
+	// ActionReview is planned when a change cannot be made by PatchCluster. Nothing is applied
+	ActionReview = "review"
+)
+
+// ErrReviewRequired is returned by ApplyCluster, along with the plan, when the desired cluster differs
+// from the current one in fields which cannot be patched
+var ErrReviewRequired = errors.New("Cluster changes require recreating the cluster or change fields set by CCP, review the plan")
+
+// patchableFields are the top level cluster fields which PatchCluster can change. Any other field is
+// treated as requiring the cluster to be recreated
+var patchableFields = map[string]bool{
+	"description":            true,
+	"workers":                true,
+	"labels":                 true,
+	"auth_list":              true,
+	"helm_charts":            true,
+	"ntp_pools":              true,
+	"ntp_servers":            true,
+	"registries_self_signed": true,
+	"registries_insecure":    true,
+	"registries_root_ca":     true,
+	"load_balancer_ip_num":   true,
+}
+
+// FieldChange is a difference between the desired and current value of a cluster field. Field is the
+// JSON path of the value, e.g. "worker_node_pool.vcpus", and the values are as decoded from JSON
+type FieldChange struct {
+	Field   string
+	Type    string
+	Current interface{}
+	Desired interface{}
+}
+
+// ClusterPlan describes how the current cluster will be changed to match the desired cluster
+type ClusterPlan struct {
+	Action  string
+	Desired *Cluster
+	// Current is nil when the cluster does not exist
+	Current *Cluster
+	Changes []FieldChange
+	// Patch is the body sent by PatchCluster for ActionPatch, holding the UUID and changed fields only
+	Patch *Cluster
+	// Result is the cluster returned by CCP once the plan has been applied
+	Result *Cluster
+}
+
+// HasChanges reports whether applying the plan would change anything
+func (p *ClusterPlan) HasChanges() bool {
+	return p.Action != ActionNone
+}
+
+func (p *ClusterPlan) String() string {
+
+	var b strings.Builder
+
+	name := ""
+	if p.Desired != nil && p.Desired.Name != nil {
+		name = *p.Desired.Name
+	}
+
+	fmt.Fprintf(&b, "cluster %s: %s\n", name, p.Action)
+
+	for _, c := range p.Changes {
+		fmt.Fprintf(&b, "  ~ %s: %s => %s (%s)\n", c.Field, planValue(c.Current), planValue(c.Desired), c.Type)
+	}
+
+	return b.String()
+}
+
+func planValue(v interface{}) string {
+
+	if v == nil {
+		return "(unset)"
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(j)
+}
+
+// PlanCluster compares the desired cluster with the cluster of the same name on CCP without making
+// any change. Only fields set in desired are compared, so fields left to CCP defaults do not show as
+// drift. Write only fields such as passwords are never compared
+func (s *Client) PlanCluster(ctx context.Context, desired *Cluster) (*ClusterPlan, error) {
+
+	if desired == nil || nonzero(desired.Name) {
+		return nil, errors.New("Cluster.Name is missing")
+	}
+
+	current, err := s.GetClusterContext(ctx, *desired.Name)
+
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil || current == nil {
+		return &ClusterPlan{Action: ActionCreate, Desired: desired}, nil
+	}
+
+	return DiffCluster(current, desired)
+}
+
+// DiffCluster plans the changes needed for current to match desired. Labels are compared as a set,
+// ignoring their order
+func DiffCluster(current, desired *Cluster) (*ClusterPlan, error) {
+
+	currentDoc, err := clusterDocument(current)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredDoc, err := clusterDocument(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	// Labels are a set, so the same labels in a different order are not a change
+	if desired != nil && desired.Labels != nil && reflect.DeepEqual(ClusterLabels(desired), ClusterLabels(current)) {
+		delete(desiredDoc, "labels")
+	}
+
+	plan := &ClusterPlan{Action: ActionNone, Desired: desired, Current: current}
+
+	diffFields("", desiredDoc, currentDoc, &plan.Changes)
+
+	patch := map[string]interface{}{}
+
+	for _, c := range plan.Changes {
+		switch c.Type {
+		case ChangePatch:
+			if plan.Action == ActionNone {
+				plan.Action = ActionPatch
+			}
+			top := strings.SplitN(c.Field, ".", 2)[0]
+			patch[top] = desiredDoc[top]
+		default:
+			plan.Action = ActionReview
+		}
+	}
+
+	if plan.Action == ActionPatch {
+		patch["uuid"] = currentDoc["uuid"]
+		plan.Patch = &Cluster{}
+		j, err := json.Marshal(patch)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(j, plan.Patch); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyCluster makes the cluster on CCP match desired. A missing cluster is created with AddCluster and
+// changes to patchable fields are sent with a single PatchCluster holding only those fields. When any
+// change needs the cluster to be recreated, or is to a field set by CCP, nothing is changed and the
+// plan is returned with ErrReviewRequired
+func (s *Client) ApplyCluster(ctx context.Context, desired *Cluster) (*ClusterPlan, error) {
+
+	plan, err := s.PlanCluster(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	switch plan.Action {
+	case ActionCreate:
+		plan.Result, err = s.AddClusterContext(ctx, desired)
+	case ActionPatch:
+		plan.Result, err = s.PatchClusterContext(ctx, plan.Patch)
+	case ActionReview:
+		err = ErrReviewRequired
+	default:
+		plan.Result = plan.Current
+	}
+
+	return plan, err
+}
+
+// clusterDocument returns the cluster as decoded JSON so fields can be compared by name
+func clusterDocument(cluster *Cluster) (map[string]interface{}, error) {
+
+	doc := map[string]interface{}{}
+
+	if cluster == nil {
+		return doc, nil
+	}
+
+	j, err := json.Marshal(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(j))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func diffFields(prefix string, desired, current map[string]interface{}, changes *[]FieldChange) {
+
+	keys := []string{}
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if isSensitive(k) {
+			continue
+		}
+
+		field := prefix + k
+		d := desired[k]
+		c := current[k]
+
+		dm, dIsMap := d.(map[string]interface{})
+		cm, cIsMap := c.(map[string]interface{})
+		if dIsMap && (cIsMap || c == nil) {
+			diffFields(field+".", dm, cm, changes)
+			continue
+		}
+
+		if reflect.DeepEqual(d, c) || (c == nil && isZeroJSON(d)) {
+			continue
+		}
+
+		*changes = append(*changes, FieldChange{
+			Field:   field,
+			Type:    changeType(field),
+			Current: c,
+			Desired: d,
+		})
+	}
+}
+
+// changeType classifies a change by the top level field it is in
+func changeType(field string) string {
+
+	top := strings.SplitN(field, ".", 2)[0]
+
+	switch {
+	case serverFields[top]:
+		return ChangeImmutable
+	case patchableFields[top]:
+		return ChangePatch
+	}
+
+	return ChangeRecreate
+}
+
+// isZeroJSON reports whether a decoded JSON value is empty, which CCP treats the same as unset
+func isZeroJSON(v interface{}) bool {
+
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case json.Number:
+		return v.String() == "0"
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, child := range v {
+			if !isZeroJSON(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
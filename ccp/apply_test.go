@@ -0,0 +1,249 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func labelList(pairs ...string) *[]Label {
+
+	list := []Label{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		list = append(list, Label{Key: String(pairs[i]), Value: String(pairs[i+1])})
+	}
+
+	return &list
+}
+
+// currentCluster is a cluster as returned by GetCluster
+func currentCluster() *Cluster {
+
+	return &Cluster{
+		UUID:           String("1234"),
+		Name:           String("demo"),
+		State:          String(StateReady),
+		Description:    String("Demo cluster"),
+		Workers:        Int64(2),
+		Labels:         labelList("env", "dev", "team", "platform"),
+		WorkerNodePool: &WorkerNodePool{VCPUs: Int64(2), Memory: Int64(16384)},
+	}
+}
+
+func TestDiffCluster(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		desired *Cluster
+		action  string
+		changes map[string]string
+		patch   *Cluster
+	}{
+		{
+			name:    "unchanged",
+			desired: &Cluster{Name: String("demo"), Workers: Int64(2)},
+			action:  ActionNone,
+			changes: map[string]string{},
+		},
+		{
+			name:    "unset fields are not drift",
+			desired: &Cluster{Name: String("demo")},
+			action:  ActionNone,
+			changes: map[string]string{},
+		},
+		{
+			name:    "patchable",
+			desired: &Cluster{Name: String("demo"), Workers: Int64(3), Description: String("Bigger")},
+			action:  ActionPatch,
+			changes: map[string]string{"workers": ChangePatch, "description": ChangePatch},
+			patch:   &Cluster{UUID: String("1234"), Workers: Int64(3), Description: String("Bigger")},
+		},
+		{
+			name:    "recreate",
+			desired: &Cluster{Name: String("demo"), WorkerNodePool: &WorkerNodePool{VCPUs: Int64(4)}},
+			action:  ActionReview,
+			changes: map[string]string{"worker_node_pool.vcpus": ChangeRecreate},
+		},
+		{
+			name:    "immutable",
+			desired: &Cluster{Name: String("demo"), State: String(StateCreating), UUID: String("5678")},
+			action:  ActionReview,
+			changes: map[string]string{"state": ChangeImmutable, "uuid": ChangeImmutable},
+		},
+		{
+			name:    "immutable with patchable",
+			desired: &Cluster{Name: String("demo"), Workers: Int64(3), State: String(StateCreating)},
+			action:  ActionReview,
+			changes: map[string]string{"workers": ChangePatch, "state": ChangeImmutable},
+		},
+		{
+			name:    "sensitive fields skipped",
+			desired: &Cluster{Name: String("demo"), SSHPassword: String("secret"), HarborAdminServerPassword: String("secret")},
+			action:  ActionNone,
+			changes: map[string]string{},
+		},
+		{
+			name:    "labels in another order",
+			desired: &Cluster{Name: String("demo"), Labels: labelList("team", "platform", "env", "dev")},
+			action:  ActionNone,
+			changes: map[string]string{},
+		},
+		{
+			name:    "label changed",
+			desired: &Cluster{Name: String("demo"), Labels: labelList("team", "platform", "env", "prod")},
+			action:  ActionPatch,
+			changes: map[string]string{"labels": ChangePatch},
+			patch:   &Cluster{UUID: String("1234"), Labels: labelList("team", "platform", "env", "prod")},
+		},
+		{
+			name:    "labels removed",
+			desired: &Cluster{Name: String("demo"), Labels: labelList()},
+			action:  ActionPatch,
+			changes: map[string]string{"labels": ChangePatch},
+			patch:   &Cluster{UUID: String("1234"), Labels: labelList()},
+		},
+	}
+
+	for _, test := range tests {
+		plan, err := DiffCluster(currentCluster(), test.desired)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if plan.Action != test.action {
+			t.Errorf("%s: Action = %s, want %s\n%s", test.name, plan.Action, test.action, plan)
+		}
+
+		changes := map[string]string{}
+		for _, c := range plan.Changes {
+			changes[c.Field] = c.Type
+		}
+		if !reflect.DeepEqual(changes, test.changes) {
+			t.Errorf("%s: Changes = %v, want %v", test.name, changes, test.changes)
+		}
+
+		if !reflect.DeepEqual(plan.Patch, test.patch) {
+			got, _ := json.Marshal(plan.Patch)
+			want, _ := json.Marshal(test.patch)
+			t.Errorf("%s: Patch = %s, want %s", test.name, got, want)
+		}
+	}
+}
+
+func TestClusterPlanString(t *testing.T) {
+
+	plan, err := DiffCluster(currentCluster(), &Cluster{Name: String("demo"), Workers: Int64(3), State: String(StateCreating)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "cluster demo: review\n" +
+		"  ~ state: \"READY\" => \"CREATING\" (immutable)\n" +
+		"  ~ workers: 2 => 3 (patch)\n"
+
+	if got := plan.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyCluster(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		desired *Cluster
+		dryRun  bool
+		action  string
+		err     error
+		request string
+	}{
+		{name: "patch", desired: &Cluster{Name: String("demo"), Workers: Int64(3)}, action: ActionPatch, request: "PATCH /2/clusters/1234"},
+		{name: "review", desired: &Cluster{Name: String("demo"), State: String(StateCreating)}, action: ActionReview, err: ErrReviewRequired},
+		{name: "unchanged", desired: &Cluster{Name: String("demo"), Workers: Int64(2)}, action: ActionNone},
+		{name: "dry run", desired: &Cluster{Name: String("demo"), Workers: Int64(3)}, dryRun: true, action: ActionPatch},
+	}
+
+	for _, test := range tests {
+		var mutations []string
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/2/clusters/demo":
+				json.NewEncoder(w).Encode(currentCluster())
+			case r.Method == "GET":
+				http.NotFound(w, r)
+			default:
+				mutations = append(mutations, r.Method+" "+r.URL.Path)
+				w.Write([]byte(`{"uuid": "1234", "name": "demo"}`))
+			}
+		}))
+
+		ctx := context.Background()
+		if test.dryRun {
+			ctx = WithDryRun(ctx)
+		}
+
+		client := NewClient("admin", "secret", srv.URL)
+
+		// A missing cluster is planned for creation
+		if plan, err := client.PlanCluster(ctx, &Cluster{Name: String("new")}); err != nil || plan.Action != ActionCreate {
+			t.Errorf("%s: PlanCluster for a missing cluster returned %v, %v, want %s", test.name, plan, err, ActionCreate)
+		}
+
+		plan, err := client.ApplyCluster(ctx, test.desired)
+
+		srv.Close()
+
+		if test.dryRun {
+			request, ok := DryRunRequest(err)
+			if !ok {
+				t.Errorf("%s: ApplyCluster returned %v, want a dry run error", test.name, err)
+				continue
+			}
+			if request.Method != "PATCH" || !strings.HasSuffix(request.URL, "/2/clusters/1234") {
+				t.Errorf("%s: planned %s %s, want PATCH /2/clusters/1234", test.name, request.Method, request.URL)
+			}
+			if want := `{"uuid":"1234","workers":3}`; string(request.Body) != want {
+				t.Errorf("%s: planned body %s, want %s", test.name, request.Body, want)
+			}
+		} else if err != test.err {
+			t.Errorf("%s: ApplyCluster returned %v, want %v", test.name, err, test.err)
+		}
+
+		if plan == nil {
+			t.Errorf("%s: no plan returned", test.name)
+			continue
+		}
+		if plan.Action != test.action {
+			t.Errorf("%s: Action = %s, want %s", test.name, plan.Action, test.action)
+		}
+
+		var want []string
+		if test.request != "" {
+			want = []string{test.request}
+		}
+		if !reflect.DeepEqual(mutations, want) {
+			t.Errorf("%s: sent %v, want %v", test.name, mutations, want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// ErrAuthzConflict is returned when the users and groups authorized on a cluster keep changing while
+// they are being updated
+var ErrAuthzConflict = errors.New("Authorization of the cluster was changed by someone else while being updated, try again")
+
+// ClusterAuthz lists the users and groups, e.g. "jsmith" or "developers", authorized on a cluster
+type ClusterAuthz struct {
+	AuthList *[]string `json:"auth_list,omitempty"`
+}
+
+// Principals returns the authorized users and groups, or an empty list
+func (a *ClusterAuthz) Principals() []string {
+
+	if a == nil || a.AuthList == nil {
+		return []string{}
+	}
+
+	return append([]string{}, *a.AuthList...)
+}
+
+// Has reports whether the user or group is authorized on the cluster
+func (a *ClusterAuthz) Has(principal string) bool {
+	return containsString(a.Principals(), principal)
+}
+
+// SetClusterAuthz replaces the users and groups authorized on a cluster. See GrantClusterAccess for how
+// concurrent changes are detected
+func (s *Client) SetClusterAuthz(clusterUUID string, principals []string) (*ClusterAuthz, error) {
+	return s.SetClusterAuthzContext(context.Background(), clusterUUID, principals)
+}
+
+// SetClusterAuthzContext is SetClusterAuthz with a context for cancellation
+func (s *Client) SetClusterAuthzContext(ctx context.Context, clusterUUID string, principals []string) (*ClusterAuthz, error) {
+
+	for _, principal := range principals {
+		if principal == "" {
+			return nil, errors.New("Principal is missing")
+		}
+	}
+
+	return s.updateClusterAuthz(ctx, clusterUUID, func(current []string) []string {
+
+		updated := []string{}
+		for _, principal := range principals {
+			if !containsString(updated, principal) {
+				updated = append(updated, principal)
+			}
+		}
+
+		return updated
+	})
+}
+
+// GrantClusterAccess authorizes a user or group on a cluster. Granting access to a principal which already
+// has it is not an error. The auth list is read, changed and written back with PatchCluster, then read
+// again to check the principals written were kept. When they were not the update is retried from the
+// start, and ErrAuthzConflict is returned when they keep changing. As with the labels this is best
+// effort, since a change made by someone else between the read and the patch goes unnoticed
+func (s *Client) GrantClusterAccess(clusterUUID, principal string) (*ClusterAuthz, error) {
+	return s.GrantClusterAccessContext(context.Background(), clusterUUID, principal)
+}
+
+// GrantClusterAccessContext is GrantClusterAccess with a context for cancellation
+func (s *Client) GrantClusterAccessContext(ctx context.Context, clusterUUID, principal string) (*ClusterAuthz, error) {
+
+	if principal == "" {
+		return nil, errors.New("Principal is missing")
+	}
+
+	return s.updateClusterAuthz(ctx, clusterUUID, func(current []string) []string {
+
+		if containsString(current, principal) {
+			return current
+		}
+
+		return append(current, principal)
+	})
+}
+
+// RevokeClusterAccess removes a user or group from those authorized on a cluster. Revoking access from a
+// principal which does not have it is not an error. See GrantClusterAccess for how concurrent changes
+// are detected
+func (s *Client) RevokeClusterAccess(clusterUUID, principal string) (*ClusterAuthz, error) {
+	return s.RevokeClusterAccessContext(context.Background(), clusterUUID, principal)
+}
+
+// RevokeClusterAccessContext is RevokeClusterAccess with a context for cancellation
+func (s *Client) RevokeClusterAccessContext(ctx context.Context, clusterUUID, principal string) (*ClusterAuthz, error) {
+
+	if principal == "" {
+		return nil, errors.New("Principal is missing")
+	}
+
+	return s.updateClusterAuthz(ctx, clusterUUID, func(current []string) []string {
+
+		updated := []string{}
+		for _, p := range current {
+			if p != principal {
+				updated = append(updated, p)
+			}
+		}
+
+		return updated
+	})
+}
+
+// updateClusterAuthz patches the auth list of a cluster with that returned by change, which is given a
+// copy of the current list, and checks it was kept
+func (s *Client) updateClusterAuthz(ctx context.Context, clusterUUID string, change func(current []string) []string) (*ClusterAuthz, error) {
+
+	if clusterUUID == "" {
+		return nil, errors.New("Cluster UUID is missing")
+	}
+
+	var updated *ClusterAuthz
+
+	err := retryUpdate(ErrAuthzConflict, func() (bool, error) {
+
+		authz, err := s.GetClusterAuthzContext(ctx, clusterUUID)
+		if err != nil {
+			return false, err
+		}
+
+		principals := change(authz.Principals())
+
+		if samePrincipals(principals, authz.Principals()) {
+			updated = &ClusterAuthz{AuthList: &principals}
+			return true, nil
+		}
+
+		if _, err := s.PatchClusterContext(ctx, &Cluster{
+			UUID:     String(clusterUUID),
+			AuthList: &principals,
+		}); err != nil {
+			return false, err
+		}
+
+		check, err := s.GetClusterAuthzContext(ctx, clusterUUID)
+		if err != nil {
+			return false, err
+		}
+		if !samePrincipals(check.Principals(), principals) {
+			return false, nil
+		}
+
+		updated = &ClusterAuthz{AuthList: &principals}
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// samePrincipals reports whether two auth lists hold the same principals, in any order
+func samePrincipals(a, b []string) bool {
+
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return reflect.DeepEqual(a, b)
+}
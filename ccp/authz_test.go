@@ -0,0 +1,166 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterAuthzUpdates(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		update  func(c *Client) (*ClusterAuthz, error)
+		want    []string
+		patches int
+		wantErr bool
+	}{
+		{
+			name:    "grant",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.GrantClusterAccess("1234", "developers") },
+			want:    []string{"admin", "jsmith", "developers"},
+			patches: 1,
+		},
+		{
+			name:   "grant unchanged",
+			update: func(c *Client) (*ClusterAuthz, error) { return c.GrantClusterAccess("1234", "jsmith") },
+			want:   []string{"admin", "jsmith"},
+		},
+		{
+			name:    "revoke",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.RevokeClusterAccess("1234", "jsmith") },
+			want:    []string{"admin"},
+			patches: 1,
+		},
+		{
+			name:   "revoke missing",
+			update: func(c *Client) (*ClusterAuthz, error) { return c.RevokeClusterAccess("1234", "developers") },
+			want:   []string{"admin", "jsmith"},
+		},
+		{
+			name: "set",
+			update: func(c *Client) (*ClusterAuthz, error) {
+				return c.SetClusterAuthz("1234", []string{"ops", "ops", "admin"})
+			},
+			want:    []string{"ops", "admin"},
+			patches: 1,
+		},
+		{
+			name:   "set reordered",
+			update: func(c *Client) (*ClusterAuthz, error) { return c.SetClusterAuthz("1234", []string{"jsmith", "admin"}) },
+			want:   []string{"admin", "jsmith"},
+		},
+		{
+			name:    "set none",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.SetClusterAuthz("1234", nil) },
+			want:    []string{},
+			patches: 1,
+		},
+		{
+			name:    "grant empty principal",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.GrantClusterAccess("1234", "") },
+			want:    []string{"admin", "jsmith"},
+			wantErr: true,
+		},
+		{
+			name:    "revoke empty principal",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.RevokeClusterAccess("1234", "") },
+			want:    []string{"admin", "jsmith"},
+			wantErr: true,
+		},
+		{
+			name:    "set empty principal",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.SetClusterAuthz("1234", []string{"ops", ""}) },
+			want:    []string{"admin", "jsmith"},
+			wantErr: true,
+		},
+		{
+			name:    "missing cluster UUID",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.GrantClusterAccess("", "developers") },
+			want:    []string{"admin", "jsmith"},
+			wantErr: true,
+		},
+		{
+			name:    "missing cluster",
+			update:  func(c *Client) (*ClusterAuthz, error) { return c.GrantClusterAccess("5678", "developers") },
+			want:    []string{"admin", "jsmith"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		srv := newClusterServer(Cluster{AuthList: &[]string{"admin", "jsmith"}})
+
+		authz, err := test.update(NewClient("admin", "secret", srv.URL))
+
+		srv.Close()
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: succeeded, want an error", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case !test.wantErr && !samePrincipals(authz.Principals(), test.want):
+			t.Errorf("%s: returned %v, want %v", test.name, authz.Principals(), test.want)
+		}
+
+		if got := (&ClusterAuthz{AuthList: srv.cluster.AuthList}).Principals(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: auth list is %v, want %v", test.name, got, test.want)
+		}
+		if srv.patches != test.patches {
+			t.Errorf("%s: sent %d patches, want %d", test.name, srv.patches, test.patches)
+		}
+	}
+}
+
+func TestClusterAuthzConflict(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		overwrites int
+		want       []string
+		patches    int
+		err        error
+	}{
+		{name: "kept", overwrites: 0, want: []string{"admin", "developers"}, patches: 1},
+		{name: "overwritten once", overwrites: 1, want: []string{"admin", "ops", "developers"}, patches: 2},
+		{name: "overwritten twice", overwrites: 2, want: []string{"admin", "ops", "developers"}, patches: 3},
+		{name: "always overwritten", overwrites: updateAttempts, want: []string{"admin", "ops"}, patches: updateAttempts, err: ErrAuthzConflict},
+	}
+
+	for _, test := range tests {
+		srv := newClusterServer(Cluster{AuthList: &[]string{"admin"}})
+		srv.overwrites = test.overwrites
+		srv.overwrite = func(cluster *Cluster) {
+			cluster.AuthList = &[]string{"admin", "ops"}
+		}
+
+		_, err := NewClient("admin", "secret", srv.URL).GrantClusterAccess("1234", "developers")
+
+		srv.Close()
+
+		if err != test.err {
+			t.Errorf("%s: GrantClusterAccess returned %v, want %v", test.name, err, test.err)
+		}
+		if !reflect.DeepEqual(*srv.cluster.AuthList, test.want) {
+			t.Errorf("%s: auth list is %v, want %v", test.name, *srv.cluster.AuthList, test.want)
+		}
+		if srv.patches != test.patches {
+			t.Errorf("%s: sent %d patches, want %d", test.name, srv.patches, test.patches)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of objects held by a Cache
+const (
+	CacheClusters              = "clusters"
+	CacheUsers                 = "users"
+	CacheProviderClientConfigs = "providerclientconfigs"
+)
+
+var cacheKinds = []string{CacheClusters, CacheUsers, CacheProviderClientConfigs}
+
+// DefaultCacheRefreshInterval is how often a started Cache is refreshed when no interval is given
+const DefaultCacheRefreshInterval = time.Minute
+
+// DefaultCacheRetryInterval is how long lookups wait after a failed list before listing again when no
+// interval is given
+const DefaultCacheRetryInterval = 10 * time.Second
+
+// CacheOptions configure a Cache
+type CacheOptions struct {
+	// RefreshInterval is how often a started cache lists every object again, DefaultCacheRefreshInterval
+	// when not set. Objects older than twice the interval are reported as stale
+	RefreshInterval time.Duration
+	// RetryInterval is how long lookups and Ensure use the objects held, or none, after a list failed
+	// before listing again, so a control plane which is down is not listed on every lookup.
+	// DefaultCacheRetryInterval when not set. Refresh and the background refresh always list
+	RetryInterval time.Duration
+}
+
+// CacheStatus describes how fresh the objects of one kind held by a Cache are
+type CacheStatus struct {
+	// Refreshed is when the objects were last listed successfully, zero if they never were
+	Refreshed time.Time
+	// Age is the time since Refreshed
+	Age time.Duration
+	// Invalidated is set from a change made through the client until the next refresh
+	Invalidated bool
+	// Err is the error of the last refresh, nil when it succeeded
+	Err error
+	// Stale is set when the objects were never listed, are older than twice the refresh interval, or
+	// the last refresh failed
+	Stale bool
+}
+
+// Cache keeps the clusters, users and provider client configs of CCP in memory, for code which looks
+// them up far more often than they change. Objects of each kind are listed on the first lookup and,
+// once Start is called, again in the background every refresh interval. Changes made through the same
+// Client, such as PatchCluster or AddUser, invalidate the kind changed so the next lookup lists it again.
+// Lookups list with a background context and do not return the error of the list; call Ensure first to
+// list with a context and get the error. The objects returned are shared by every caller and must not
+// be modified
+type Cache struct {
+	client   *Client
+	interval time.Duration
+	retry    time.Duration
+
+	mu            sync.RWMutex
+	clusters      map[string]Cluster
+	clusterNames  map[string]string
+	clusterLabels map[string]map[string][]string
+	users         map[string]User
+	providers     map[string]ProviderClientConfig
+	providerNames map[string]string
+	status        map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	// refreshMu stops the objects of the kind being listed by more than one caller at once, without
+	// holding up the other kinds
+	refreshMu sync.Mutex
+
+	refreshed   time.Time
+	invalidated bool
+	// invalidations counts the calls to Invalidate, so a change made while a list is in flight is not
+	// forgotten when the list completes
+	invalidations int
+	err           error
+	// failed is when the last list failed, which lookups wait the retry interval after
+	failed time.Time
+}
+
+// NewCache creates a cache of the objects of client. It adds a middleware to client which invalidates
+// the cache when a request changing CCP succeeds
+func NewCache(client *Client, opts CacheOptions) *Cache {
+
+	c := &Cache{
+		client:   client,
+		interval: opts.RefreshInterval,
+		retry:    opts.RetryInterval,
+		status:   map[string]*cacheEntry{},
+	}
+
+	if c.interval <= 0 {
+		c.interval = DefaultCacheRefreshInterval
+	}
+	if c.retry <= 0 {
+		c.retry = DefaultCacheRetryInterval
+	}
+
+	for _, kind := range cacheKinds {
+		c.status[kind] = &cacheEntry{}
+	}
+
+	client.Use(c.invalidation)
+
+	return c
+}
+
+// Start lists every object, then refreshes the cache in the background every refresh interval until ctx
+// is done. The error of the first refresh is returned, but the background refresh is started regardless
+func (c *Cache) Start(ctx context.Context) error {
+
+	err := c.Refresh(ctx)
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Refresh(ctx)
+			}
+		}
+	}()
+
+	return err
+}
+
+// Refresh lists the objects of every kind again. Objects of a kind whose list fails are kept
+func (c *Cache) Refresh(ctx context.Context) error {
+
+	var failed []string
+
+	for _, kind := range cacheKinds {
+		if err := c.refresh(ctx, kind, true); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", kind, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Refreshing the cache failed for %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// Ensure lists the objects of each kind, or of every kind when none is given, which were never listed
+// or have been invalidated, as a lookup does but with ctx. It returns the error of the last list of any
+// kind which failed, including a list failed within the retry interval which is not tried again
+func (c *Cache) Ensure(ctx context.Context, kinds ...string) error {
+
+	if len(kinds) == 0 {
+		kinds = cacheKinds
+	}
+
+	var failed []string
+
+	for _, kind := range kinds {
+		if _, ok := c.status[kind]; !ok {
+			return fmt.Errorf("Unknown cache kind %q", kind)
+		}
+		if err := c.ensure(ctx, kind); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", kind, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Listing the cache failed for %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// Invalidate marks the objects of a kind as out of date, so the next lookup lists them again
+func (c *Cache) Invalidate(kind string) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.status[kind]; ok {
+		entry.invalidated = true
+		entry.invalidations++
+	}
+}
+
+// Status returns how fresh the objects of a kind are
+func (c *Cache) Status(kind string) CacheStatus {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.status[kind]
+	if !ok {
+		return CacheStatus{Stale: true, Err: fmt.Errorf("Unknown cache kind %q", kind)}
+	}
+
+	status := CacheStatus{
+		Refreshed:   entry.refreshed,
+		Invalidated: entry.invalidated,
+		Err:         entry.err,
+	}
+
+	if !entry.refreshed.IsZero() {
+		status.Age = time.Since(entry.refreshed)
+	}
+
+	status.Stale = entry.refreshed.IsZero() || entry.err != nil || status.Age > 2*c.interval
+
+	return status
+}
+
+// invalidation is the middleware invalidating the kind of object changed by a successful request
+func (c *Cache) invalidation(next http.RoundTripper) http.RoundTripper {
+
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+		resp, err := next.RoundTrip(req)
+
+		if err == nil && req.Method != http.MethodGet && resp.StatusCode < 300 {
+			template := EndpointTemplate(req)
+			switch {
+			case strings.HasPrefix(template, "/clusters"):
+				c.Invalidate(CacheClusters)
+			case strings.HasPrefix(template, "/localusers"):
+				c.Invalidate(CacheUsers)
+			case strings.HasPrefix(template, "/providerclientconfigs"):
+				c.Invalidate(CacheProviderClientConfigs)
+			}
+		}
+
+		return resp, err
+	})
+}
+
+// ensure lists the objects of a kind when they were never listed or have been invalidated, and returns
+// the error of the last list
+func (c *Cache) ensure(ctx context.Context, kind string) error {
+
+	if c.needed(kind) {
+		return c.refresh(ctx, kind, false)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.status[kind].err
+}
+
+// needed reports whether a lookup should list the objects of a kind, which it does not within the retry
+// interval of a failed list
+func (c *Cache) needed(kind string) bool {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry := c.status[kind]
+
+	if entry.err != nil && time.Since(entry.failed) < c.retry {
+		return false
+	}
+
+	return entry.refreshed.IsZero() || entry.invalidated
+}
+
+// refresh lists the objects of a kind and replaces those held, or records the error. Unless force is
+// set nothing is listed when another caller refreshed the objects, or failed to, while this one waited
+func (c *Cache) refresh(ctx context.Context, kind string, force bool) error {
+
+	entry := c.status[kind]
+
+	entry.refreshMu.Lock()
+	defer entry.refreshMu.Unlock()
+
+	if !force && !c.needed(kind) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return entry.err
+	}
+
+	c.mu.RLock()
+	invalidations := entry.invalidations
+	c.mu.RUnlock()
+
+	var (
+		clusters  []Cluster
+		users     []User
+		providers []ProviderClientConfig
+		err       error
+	)
+
+	switch kind {
+	case CacheClusters:
+		clusters, err = c.client.GetClustersContext(ctx)
+	case CacheUsers:
+		users, err = c.client.ListUsersContext(ctx, ListOptions{})
+	case CacheProviderClientConfigs:
+		providers, err = c.client.ListProviderClientConfigsContext(ctx, ListOptions{})
+	}
+
+	// A list given up by the caller says nothing about CCP, so it is neither recorded nor waited after
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.err = err
+
+	if err != nil {
+		entry.failed = time.Now()
+		return err
+	}
+
+	// Objects invalidated while the list was in flight may be older than the change, so stay invalidated
+	entry.invalidated = entry.invalidations != invalidations
+	entry.refreshed = time.Now()
+
+	switch kind {
+	case CacheClusters:
+		c.clusters = map[string]Cluster{}
+		c.clusterNames = map[string]string{}
+		c.clusterLabels = map[string]map[string][]string{}
+		for _, cluster := range clusters {
+			uuid := derefString(cluster.UUID)
+			c.clusters[uuid] = cluster
+			c.clusterNames[derefString(cluster.Name)] = uuid
+			for key, value := range ClusterLabels(&cluster) {
+				if c.clusterLabels[key] == nil {
+					c.clusterLabels[key] = map[string][]string{}
+				}
+				c.clusterLabels[key][value] = append(c.clusterLabels[key][value], uuid)
+			}
+		}
+	case CacheUsers:
+		c.users = map[string]User{}
+		for _, user := range users {
+			c.users[derefString(user.Username)] = user
+		}
+	case CacheProviderClientConfigs:
+		c.providers = map[string]ProviderClientConfig{}
+		c.providerNames = map[string]string{}
+		for _, provider := range providers {
+			uuid := derefString(provider.UUID)
+			c.providers[uuid] = provider
+			c.providerNames[derefString(provider.Name)] = uuid
+		}
+	}
+
+	return nil
+}
+
+// Cluster returns the cluster with the UUID
+func (c *Cache) Cluster(uuid string) (*Cluster, bool) {
+
+	c.ensure(context.Background(), CacheClusters)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cluster, ok := c.clusters[uuid]
+
+	if !ok {
+		return nil, false
+	}
+
+	return &cluster, true
+}
+
+// ClusterByName returns the cluster with the name
+func (c *Cache) ClusterByName(name string) (*Cluster, bool) {
+
+	c.ensure(context.Background(), CacheClusters)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cluster, ok := c.clusters[c.clusterNames[name]]
+
+	if !ok {
+		return nil, false
+	}
+
+	return &cluster, true
+}
+
+// Clusters returns every cluster, sorted by name
+func (c *Cache) Clusters() []Cluster {
+
+	c.ensure(context.Background(), CacheClusters)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clusters := make([]Cluster, 0, len(c.clusters))
+	for _, cluster := range c.clusters {
+		clusters = append(clusters, cluster)
+	}
+
+	return sortClusters(clusters)
+}
+
+// ClustersWithLabel returns the clusters labelled key=value, sorted by name
+func (c *Cache) ClustersWithLabel(key, value string) []Cluster {
+
+	c.ensure(context.Background(), CacheClusters)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clusters := []Cluster{}
+	for _, uuid := range c.clusterLabels[key][value] {
+		clusters = append(clusters, c.clusters[uuid])
+	}
+
+	return sortClusters(clusters)
+}
+
+// SelectClusters returns the clusters matching a label selector, sorted by name. See LabelSelector
+func (c *Cache) SelectClusters(selector string) ([]Cluster, error) {
+
+	parsed, err := ParseLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []Cluster{}
+	for _, cluster := range c.Clusters() {
+		if parsed.Matches(ClusterLabels(&cluster)) {
+			selected = append(selected, cluster)
+		}
+	}
+
+	return selected, nil
+}
+
+func sortClusters(clusters []Cluster) []Cluster {
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return derefString(clusters[i].Name) < derefString(clusters[j].Name)
+	})
+
+	return clusters
+}
+
+// User returns the local user with the username
+func (c *Cache) User(username string) (*User, bool) {
+
+	c.ensure(context.Background(), CacheUsers)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	user, ok := c.users[username]
+
+	if !ok {
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// Users returns every local user, sorted by username
+func (c *Cache) Users() []User {
+
+	c.ensure(context.Background(), CacheUsers)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	users := make([]User, 0, len(c.users))
+	for _, user := range c.users {
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return derefString(users[i].Username) < derefString(users[j].Username)
+	})
+
+	return users
+}
+
+// ProviderClientConfig returns the provider client config with the UUID
+func (c *Cache) ProviderClientConfig(uuid string) (*ProviderClientConfig, bool) {
+
+	c.ensure(context.Background(), CacheProviderClientConfigs)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	provider, ok := c.providers[uuid]
+
+	if !ok {
+		return nil, false
+	}
+
+	return &provider, true
+}
+
+// ProviderClientConfigByName returns the provider client config with the name
+func (c *Cache) ProviderClientConfigByName(name string) (*ProviderClientConfig, bool) {
+
+	c.ensure(context.Background(), CacheProviderClientConfigs)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	provider, ok := c.providers[c.providerNames[name]]
+
+	if !ok {
+		return nil, false
+	}
+
+	return &provider, true
+}
+
+// ProviderClientConfigs returns every provider client config, sorted by name
+func (c *Cache) ProviderClientConfigs() []ProviderClientConfig {
+
+	c.ensure(context.Background(), CacheProviderClientConfigs)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	providers := make([]ProviderClientConfig, 0, len(c.providers))
+	for _, provider := range c.providers {
+		providers = append(providers, provider)
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return derefString(providers[i].Name) < derefString(providers[j].Name)
+	})
+
+	return providers
+}
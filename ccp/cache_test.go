@@ -0,0 +1,279 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer lists one cluster and one user, failing each list while fail is set
+type cacheServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	fail  bool
+	lists map[string]int
+	// block, when set, holds up the cluster list until it is closed
+	block chan struct{}
+	// listing is sent to when a blocked cluster list arrives
+	listing chan struct{}
+}
+
+func newCacheServer() *cacheServer {
+
+	s := &cacheServer{lists: map[string]int{}}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.lists[r.URL.Path]++
+		fail, block := s.fail, s.block
+		s.mu.Unlock()
+
+		if block != nil && r.URL.Path == "/2/clusters" {
+			s.listing <- struct{}{}
+			<-block
+		}
+
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/2/clusters":
+			json.NewEncoder(w).Encode([]Cluster{{UUID: String("1234"), Name: String("demo")}})
+		case "/2/localusers":
+			json.NewEncoder(w).Encode([]User{{Username: String("admin")}})
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+
+	return s
+}
+
+func (s *cacheServer) setFail(fail bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+}
+
+func (s *cacheServer) listed(path string) int {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lists[path]
+}
+
+func TestCacheInvalidation(t *testing.T) {
+
+	srv := newCacheServer()
+	defer srv.Close()
+
+	// Lookups list again straight after a failed list
+	cache := NewCache(NewClient("admin", "secret", srv.URL), CacheOptions{RetryInterval: time.Nanosecond})
+
+	if _, ok := cache.Cluster("1234"); !ok {
+		t.Fatal("Cluster 1234 not found")
+	}
+
+	steps := []struct {
+		name       string
+		invalidate bool
+		fail       bool
+		// lists is the number of cluster lists sent so far
+		lists           int
+		wantInvalidated bool
+		wantErr         bool
+	}{
+		{name: "fresh", lists: 1},
+		{name: "invalidated and the list failed", invalidate: true, fail: true, lists: 2, wantInvalidated: true, wantErr: true},
+		{name: "list failed again", fail: true, lists: 3, wantInvalidated: true, wantErr: true},
+		{name: "refreshed", lists: 4},
+		{name: "not listed again", lists: 4},
+	}
+
+	for _, step := range steps {
+		if step.invalidate {
+			cache.Invalidate(CacheClusters)
+		}
+		srv.setFail(step.fail)
+
+		// A lookup lists the clusters again only while they are invalidated, and keeps the last
+		// clusters listed when the list fails
+		if _, ok := cache.Cluster("1234"); !ok {
+			t.Errorf("%s: cluster 1234 not found", step.name)
+		}
+
+		status := cache.Status(CacheClusters)
+		if status.Invalidated != step.wantInvalidated {
+			t.Errorf("%s: Invalidated is %v, want %v", step.name, status.Invalidated, step.wantInvalidated)
+		}
+		if (status.Err != nil) != step.wantErr {
+			t.Errorf("%s: Err is %v, want an error %v", step.name, status.Err, step.wantErr)
+		}
+		if lists := srv.listed("/2/clusters"); lists != step.lists {
+			t.Errorf("%s: listed clusters %d times, want %d", step.name, lists, step.lists)
+		}
+	}
+}
+
+func TestCacheRefreshFailureKeepsInvalidation(t *testing.T) {
+
+	srv := newCacheServer()
+	defer srv.Close()
+
+	cache := NewCache(NewClient("admin", "secret", srv.URL), CacheOptions{})
+
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Invalidate(CacheUsers)
+	srv.setFail(true)
+
+	if err := cache.Refresh(context.Background()); err == nil {
+		t.Error("Refresh succeeded while every list failed")
+	}
+	if status := cache.Status(CacheUsers); !status.Invalidated || !status.Stale {
+		t.Errorf("Status after a failed refresh is %+v, want invalidated and stale", status)
+	}
+}
+
+func TestCacheRetryInterval(t *testing.T) {
+
+	srv := newCacheServer()
+	defer srv.Close()
+
+	srv.setFail(true)
+
+	cache := NewCache(NewClient("admin", "secret", srv.URL), CacheOptions{RetryInterval: time.Hour})
+
+	steps := []struct {
+		name   string
+		lookup func() error
+		// lists is the number of user lists sent so far
+		lists   int
+		wantErr bool
+	}{
+		{name: "first lookup", lookup: func() error { cache.Users(); return nil }, lists: 1},
+		{name: "lookup within the retry interval", lookup: func() error { cache.User("admin"); return nil }, lists: 1},
+		{name: "ensure within the retry interval", lookup: func() error { return cache.Ensure(context.Background(), CacheUsers) }, lists: 1, wantErr: true},
+		{name: "refresh", lookup: func() error { return cache.Refresh(context.Background()) }, lists: 2, wantErr: true},
+		{name: "invalidated within the retry interval", lookup: func() error { cache.Invalidate(CacheUsers); cache.Users(); return nil }, lists: 2},
+	}
+
+	for _, step := range steps {
+		err := step.lookup()
+
+		if (err != nil) != step.wantErr {
+			t.Errorf("%s: returned %v, want an error %v", step.name, err, step.wantErr)
+		}
+		if lists := srv.listed("/2/localusers"); lists != step.lists {
+			t.Errorf("%s: listed users %d times, want %d", step.name, lists, step.lists)
+		}
+	}
+
+	if status := cache.Status(CacheUsers); status.Err == nil || !status.Stale {
+		t.Errorf("Status is %+v, want the error of the last list", status)
+	}
+}
+
+func TestCacheEnsure(t *testing.T) {
+
+	srv := newCacheServer()
+	defer srv.Close()
+
+	cache := NewCache(NewClient("admin", "secret", srv.URL), CacheOptions{RetryInterval: time.Hour})
+
+	// A list given up by the caller is not recorded as a failure to wait after
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := cache.Ensure(cancelled, CacheClusters); err == nil {
+		t.Error("Ensure with a cancelled context succeeded")
+	}
+	if status := cache.Status(CacheClusters); status.Err != nil {
+		t.Errorf("Err is %v after a cancelled list, want none", status.Err)
+	}
+
+	if err := cache.Ensure(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lists := srv.listed("/2/clusters"); lists != 1 {
+		t.Errorf("Listed clusters %d times, want 1", lists)
+	}
+
+	// The objects are held, so a lookup lists nothing
+	if _, ok := cache.ClusterByName("demo"); !ok {
+		t.Error("Cluster demo not found")
+	}
+	if lists := srv.listed("/2/clusters") + srv.listed("/2/localusers") + srv.listed("/2/providerclientconfigs"); lists != 3 {
+		t.Errorf("Sent %d lists, want one of each kind", lists)
+	}
+
+	if err := cache.Ensure(context.Background(), "nodes"); err == nil {
+		t.Error("Ensure accepted an unknown kind")
+	}
+}
+
+func TestCacheRefreshesKindsIndependently(t *testing.T) {
+
+	srv := newCacheServer()
+	defer srv.Close()
+
+	srv.mu.Lock()
+	srv.block = make(chan struct{})
+	srv.listing = make(chan struct{})
+	srv.mu.Unlock()
+
+	cache := NewCache(NewClient("admin", "secret", srv.URL), CacheOptions{})
+
+	done := make(chan struct{})
+	go func() {
+		cache.Clusters()
+		close(done)
+	}()
+	<-srv.listing
+
+	// The users are listed while the clusters are still being listed
+	users := make(chan []User)
+	go func() {
+		users <- cache.Users()
+	}()
+
+	select {
+	case got := <-users:
+		if len(got) != 1 {
+			t.Errorf("Users returned %d users, want 1", len(got))
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Listing users waited for the cluster list")
+	}
+
+	close(srv.block)
+	<-done
+}
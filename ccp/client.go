@@ -16,23 +16,74 @@ or implied.*/
 package ccp
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 )
 
 //import "encoding/json"
 
+// DefaultAPIVersion is the API version prefix used when Client.APIVersion is not set
+const DefaultAPIVersion = "2"
+
 type Client struct {
-	Username string
-	Password string
-	BaseURL  string
+	Username   string
+	Password   string `json:"-"`
+	BaseURL    string
+	APIVersion string
+
+	// Credentials, when set, is used by Login instead of Username and Password
+	Credentials CredentialsProvider `json:"-"`
+
+	// Token, when set, is sent with every request in addition to any session cookie. TokenHeader
+	// names the header used and defaults to "Authorization" with a "Bearer " prefix
+	Token       string `json:"-"`
+	TokenHeader string
+
+	// HTTPClient, when set, is used to send requests. Its transport is wrapped by any middleware
+	// registered with Use. By default a client with its own cookie jar is used
+	HTTPClient *http.Client `json:"-"`
+
+	// Tracer and Metrics, when set, are told about every request. See the ccpotel and ccpprom packages
+	// for OpenTelemetry and Prometheus implementations
+	Tracer  Tracer  `json:"-"`
+	Metrics Metrics `json:"-"`
+
+	// Logger, when set, receives a record of every request with its method, URL, status and latency.
+	// LogBodies adds the request and response bodies, with passwords and keys redacted
+	Logger    Logger `json:"-"`
+	LogBodies bool
+
+	// DryRun, when set, stops the methods which change CCP, such as AddCluster or DeleteUser, from
+	// sending their request. They validate and default as usual and return a DryRunError holding the
+	// request instead. Requests which only read, such as the provider lookup of AddClusterBasic, are sent
+	DryRun bool
+
+	mu            sync.Mutex
+	baseRaw       string
+	base          *url.URL
+	defaultClient *http.Client
+	middleware    []Middleware
+	chain         http.RoundTripper
 }
 
-var jar, err = cookiejar.New(nil)
+// Endpoint describes a CCP API path relative to the API version prefix. Each {placeholder} in
+// Template is replaced, in order, with the path escaped value from Params
+type Endpoint struct {
+	Template string
+	Params   []string
+	Query    url.Values
+}
 
 func NewClient(username, password, baseURL string) *Client {
 
@@ -43,37 +94,350 @@ func NewClient(username, password, baseURL string) *Client {
 	}
 }
 
-func (s *Client) doRequest(req *http.Request) ([]byte, error) {
+// NewClientWithCredentials creates a client which obtains its login credentials from the provider,
+// e.g. EnvCredentials("", "") or FileCredentials("/etc/ccp/credentials.json")
+func NewClientWithCredentials(baseURL string, credentials CredentialsProvider) *Client {
+
+	return &Client{
+		BaseURL:     baseURL,
+		Credentials: credentials,
+	}
+}
+
+// String describes the client without its password
+func (s *Client) String() string {
+	return fmt.Sprintf("&{Username:%s BaseURL:%s APIVersion:%s}", s.Username, s.BaseURL, s.APIVersion)
+}
+
+// GoString describes the client without its password
+func (s *Client) GoString() string {
+	return fmt.Sprintf("&ccp.Client{Username:%q, BaseURL:%q, APIVersion:%q}", s.Username, s.BaseURL, s.APIVersion)
+}
 
-	var client *http.Client
+func endpoint(template string, params ...string) Endpoint {
+	return Endpoint{Template: template, Params: params}
+}
+
+// Path returns the escaped path of the endpoint with all placeholders substituted
+func (e Endpoint) Path() (string, error) {
+
+	var path strings.Builder
+
+	params := e.Params
+	rest := e.Template
+
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			path.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("Endpoint %s has an unterminated placeholder", e.Template)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		if len(params) == 0 {
+			return "", fmt.Errorf("Endpoint %s is missing a value for {%s}", e.Template, name)
+		}
+		if params[0] == "" {
+			return "", fmt.Errorf("Endpoint %s requires a non-empty value for {%s}", e.Template, name)
+		}
+
+		path.WriteString(rest[:start])
+		path.WriteString(escapeSegment(params[0]))
+
+		params = params[1:]
+		rest = rest[end+1:]
+	}
 
-	req.Header.Add("Content-Type", "application/json")
-	//req.SetBasicAuth(s.Username, s.Password)
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	if len(params) != 0 {
+		return "", fmt.Errorf("Endpoint %s was given %d unused values", e.Template, len(params))
 	}
-	client = &http.Client{Transport: tr, Jar: jar}
 
-	resp, err := client.Do(req)
+	return path.String(), nil
+}
+
+// escapeSegment escapes a single path segment. Dot segments are escaped as well so that
+// they cannot be collapsed into the parent path by a proxy or the server
+func escapeSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.Replace(segment, ".", "%2E", -1)
+	}
+	return url.PathEscape(segment)
+}
+
+// baseURL parses BaseURL, reusing the previous result for as long as BaseURL is unchanged
+func (s *Client) baseURL() (*url.URL, error) {
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.base != nil && s.baseRaw == s.BaseURL {
+		return s.base, nil
+	}
+
+	base, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("BaseURL is invalid: %v", err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("BaseURL %q must include a scheme and host", s.BaseURL)
+	}
+
+	s.base = base
+	s.baseRaw = s.BaseURL
+
+	return base, nil
+}
+
+// URL resolves the endpoint against BaseURL and the API version prefix
+func (s *Client) URL(e Endpoint) (*url.URL, error) {
+
+	base, err := s.baseURL()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+
+	path, err := e.Path()
 	if err != nil {
 		return nil, err
 	}
 
-	if 200 != resp.StatusCode && 201 != resp.StatusCode && 202 != resp.StatusCode && 204 != resp.StatusCode {
-		return nil, fmt.Errorf("%s", body)
+	u := *base
+	u.RawPath = strings.TrimRight(base.EscapedPath(), "/") + "/" + escapeSegment(s.apiVersion()) + path
+	u.Path, err = url.PathUnescape(u.RawPath)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = e.Query.Encode()
+	u.Fragment = ""
+
+	return &u, nil
+}
+
+func (s *Client) apiVersion() string {
+
+	version := strings.Trim(s.APIVersion, "/")
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+
+	return version
+}
+
+// NewRequest builds a request for the given endpoint. A url.Values body is sent as a form post,
+// any other non-nil body is encoded as JSON
+func (s *Client) NewRequest(method string, e Endpoint, body interface{}) (*http.Request, error) {
+	return s.NewRequestWithContext(context.Background(), method, e, body)
+}
+
+// NewRequestWithContext is NewRequest for a request which is cancelled along with ctx
+func (s *Client) NewRequestWithContext(ctx context.Context, method string, e Endpoint, body interface{}) (*http.Request, error) {
+
+	u, err := s.URL(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf io.Reader
+
+	contentType := "application/json"
+
+	switch b := body.(type) {
+	case nil:
+	case url.Values:
+		contentType = "application/x-www-form-urlencoded"
+		buf = strings.NewReader(b.Encode())
+	default:
+		j, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		buf = bytes.NewReader(j)
+	}
+
+	req, err := http.NewRequest(method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	return req.WithContext(context.WithValue(ctx, endpointKey{}, e.Template)), nil
+}
+
+// httpClient returns the client used to send requests, with the middleware chain applied
+func (s *Client) httpClient() *http.Client {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client := s.baseClient()
+
+	if len(s.middleware) == 0 && s.Logger == nil && s.Tracer == nil && s.Metrics == nil {
+		return client
+	}
+
+	// The chain is built once, when the first request is sent after Use, so middleware holding state
+	// across requests keeps it. The innermost transport looks up HTTPClient, Logger, Tracer and Metrics
+	// on every request, so changing them does not rebuild the chain
+	if s.chain == nil {
+		var transport http.RoundTripper = clientTransport{client: s}
+
+		// The first middleware registered is the outermost
+		for i := len(s.middleware) - 1; i >= 0; i-- {
+			transport = s.middleware[i](transport)
+		}
+
+		s.chain = transport
+	}
+
+	chained := *client
+	chained.Transport = s.chain
+
+	return &chained
+}
+
+// baseClient returns HTTPClient, or a default client with its own cookie jar. s.mu must be held
+func (s *Client) baseClient() *http.Client {
+
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+
+	if s.defaultClient == nil {
+		jar, _ := cookiejar.New(nil)
+		tr := &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+		s.defaultClient = &http.Client{Transport: tr, Jar: jar}
+	}
+
+	return s.defaultClient
+}
+
+// clientTransport is the innermost transport of the middleware chain. It sends requests with the
+// transport of the current HTTPClient, wrapped for logging and instrumentation when they are enabled
+type clientTransport struct {
+	client *Client
+}
+
+func (t clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	s := t.client
+
+	s.mu.Lock()
+	transport := s.baseClient().Transport
+	logger, bodies := s.Logger, s.LogBodies
+	instrumented := s.Tracer != nil || s.Metrics != nil
+	s.mu.Unlock()
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if instrumented {
+		transport = attemptTransport{next: transport}
+	}
+
+	if logger != nil {
+		transport = &loggingTransport{next: transport, logger: logger, bodies: bodies}
 	}
 
+	return transport.RoundTrip(req)
+}
+
+func (s *Client) doRequest(req *http.Request) ([]byte, error) {
+
+	body, err := s.doStream(req)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
+
+	return ioutil.ReadAll(body)
+}
+
+// doStream sends the request and returns the body of a successful response for the caller to read and
+// close. The request is reported to the Tracer and Metrics when the body is closed
+func (s *Client) doStream(req *http.Request) (io.ReadCloser, error) {
+
+	s.authorize(req)
+
+	req, finish := s.instrument(req)
+
+	resp, err := s.httpClient().Do(req)
+
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+
+	if 200 != resp.StatusCode && 201 != resp.StatusCode && 202 != resp.StatusCode && 204 != resp.StatusCode {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			finish(resp.StatusCode, err)
+			return nil, err
+		}
+		err = &APIError{StatusCode: resp.StatusCode, Body: body}
+		finish(resp.StatusCode, err)
+		return nil, err
+	}
+
+	return &responseBody{ReadCloser: resp.Body, status: resp.StatusCode, finish: finish}, nil
+}
+
+// responseBody reports the outcome of a request, including any error reading its body, when closed
+type responseBody struct {
+	io.ReadCloser
+	status int
+	finish func(status int, err error)
+	err    error
+	closed bool
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+
+	err := b.ReadCloser.Close()
+
+	if !b.closed {
+		b.closed = true
+		b.finish(b.status, b.err)
+	}
+
+	return err
+}
+
+// APIError is returned when CCP responds with an error status. The message is the response body
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return string(e.Body)
+}
+
+// IsNotFound reports whether err is an APIError for a 404 Not Found response
+func IsNotFound(err error) bool {
+
+	apiErr, ok := err.(*APIError)
 
-	return body, nil
+	return ok && apiErr.StatusCode == http.StatusNotFound
 }
 
 // Helper routine used to return pointer - will used to simplify the use of the clientlibrary
@@ -0,0 +1,127 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestEndpointPathEscaping(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		param string
+		want  string
+	}{
+		{name: "plain", param: "demo", want: "/clusters/demo"},
+		{name: "slash", param: "a/b", want: "/clusters/a%2Fb"},
+		{name: "question mark", param: "a?b=c", want: "/clusters/a%3Fb=c"},
+		{name: "percent", param: "100%", want: "/clusters/100%25"},
+		{name: "escaped slash", param: "a%2Fb", want: "/clusters/a%252Fb"},
+		{name: "dot", param: ".", want: "/clusters/%2E"},
+		{name: "dot dot", param: "..", want: "/clusters/%2E%2E"},
+		{name: "dots in name", param: "a..b", want: "/clusters/a..b"},
+		{name: "space", param: "my cluster", want: "/clusters/my%20cluster"},
+	}
+
+	for _, test := range tests {
+		got, err := endpoint("/clusters/{name}", test.param).Path()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: Path() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEndpointPathErrors(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+	}{
+		{name: "missing value", endpoint: endpoint("/clusters/{uuid}")},
+		{name: "empty value", endpoint: endpoint("/clusters/{uuid}", "")},
+		{name: "unused value", endpoint: endpoint("/clusters", "extra")},
+		{name: "unterminated", endpoint: endpoint("/clusters/{uuid", "1234")},
+	}
+
+	for _, test := range tests {
+		if path, err := test.endpoint.Path(); err == nil {
+			t.Errorf("%s: Path() = %q, want an error", test.name, path)
+		}
+	}
+}
+
+func TestURLJoinsBaseURL(t *testing.T) {
+
+	tests := []struct {
+		base  string
+		param string
+		want  string
+	}{
+		{base: "https://ccp.example.com", param: "demo", want: "https://ccp.example.com/2/clusters/demo"},
+		{base: "https://ccp.example.com/", param: "demo", want: "https://ccp.example.com/2/clusters/demo"},
+		{base: "https://ccp.example.com/proxy/", param: "demo", want: "https://ccp.example.com/proxy/2/clusters/demo"},
+		{base: "https://ccp.example.com/", param: "../../system", want: "https://ccp.example.com/2/clusters/..%2F..%2Fsystem"},
+		{base: "https://ccp.example.com/", param: "..", want: "https://ccp.example.com/2/clusters/%2E%2E"},
+		{base: "https://ccp.example.com/", param: "a?admin=true", want: "https://ccp.example.com/2/clusters/a%3Fadmin=true"},
+	}
+
+	for _, test := range tests {
+		client := NewClient("admin", "secret", test.base)
+
+		u, err := client.URL(endpoint("/clusters/{name}", test.param))
+		if err != nil {
+			t.Errorf("%s %s: %v", test.base, test.param, err)
+			continue
+		}
+		if u.String() != test.want {
+			t.Errorf("%s %s: URL() = %s, want %s", test.base, test.param, u, test.want)
+		}
+		if u.RawQuery != "" {
+			t.Errorf("%s %s: URL() has query %q", test.base, test.param, u.RawQuery)
+		}
+	}
+}
+
+func TestURLQuery(t *testing.T) {
+
+	client := NewClient("admin", "secret", "https://ccp.example.com/")
+
+	e := endpoint("/clusters")
+	e.Query = url.Values{"name": {"a&b=c"}}
+
+	u, err := client.URL(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://ccp.example.com/2/clusters?name=a%26b%3Dc"; u.String() != want {
+		t.Errorf("URL() = %s, want %s", u, want)
+	}
+}
+
+func TestURLRejectsInvalidBaseURL(t *testing.T) {
+
+	for _, base := range []string{"", "ccp.example.com", "https://", "://bad"} {
+		if u, err := NewClient("admin", "secret", base).URL(endpoint("/clusters")); err == nil {
+			t.Errorf("URL() with BaseURL %q = %s, want an error", base, u)
+		}
+	}
+}
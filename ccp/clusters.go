@@ -16,15 +16,21 @@ or implied.*/
 package ccp
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
 
 	validator "gopkg.in/validator.v2"
 )
 
+// States reported in Cluster.State and Node.State
+const (
+	StateCreating = "CREATING"
+	StateReady    = "READY"
+	StateDeleting = "DELETING"
+	StateError    = "ERROR"
+)
+
 //ClusterAPIResponse
 type Cluster struct {
 	UUID                      *string         `json:"uuid,omitempty"`
@@ -38,14 +44,14 @@ type Cluster struct {
 	Cluster                   *string         `json:"cluster,omitempty" validate:"nonzero"`
 	ResourcePool              *string         `json:"resource_pool,omitempty"  validate:"nonzero"`
 	Workers                   *int64          `json:"workers,omitempty"  validate:"nonzero"`
-	VCPUs                     *int64          `json:"vcpus,omitempty"  "`
+	VCPUs                     *int64          `json:"vcpus,omitempty"`
 	Memory                    *int64          `json:"memory,omitempty"  `
 	Type                      *int64          `json:"type,omitempty"  `
 	Masters                   *int64          `json:"masters,omitempty"  validate:"nonzero"`
 	State                     *string         `json:"state,omitempty"`
 	Template                  *string         `json:"template,omitempty"   `
 	SSHUser                   *string         `json:"ssh_user,omitempty"  validate:"nonzero"`
-	SSHPassword               *string         `json:"ssh_password,omitempty"`
+	SSHPassword               *string         `json:"ssh_password,omitempty" sensitive:"true"`
 	SSHKey                    *string         `json:"ssh_key,omitempty"   validate:"nonzero"`
 	Labels                    *[]Label        `json:"labels,omitempty"`
 	Nodes                     *[]Node         `json:"nodes,omitempty"`
@@ -54,7 +60,7 @@ type Cluster struct {
 	ClusterEnvURL             *string         `json:"cluster_env_url,omitempty"`
 	ClusterDashboardURL       *string         `json:"cluster_dashboard_url,omitempty"`
 	NetworkPlugin             *NetworkPlugin  `json:"network_plugin,omitempty" validate:"nonzero"`
-	CCPPrivateSSHKey          *string         `json:"ccp_private_ssh_key,omitempty"`
+	CCPPrivateSSHKey          *string         `json:"ccp_private_ssh_key,omitempty" sensitive:"true"`
 	CCPPublicSSHKey           *string         `json:"ccp_public_ssh_key,omitempty"`
 	NTPPools                  *[]string       `json:"ntp_pools,omitempty"`
 	NTPServers                *[]string       `json:"ntp_servers,omitempty"`
@@ -73,7 +79,7 @@ type Cluster struct {
 	MasterMACAddresses        *[]string       `json:"master_mac_addresses,omitempty"`
 	AuthList                  *[]string       `json:"auth_list,omitempty"`
 	IsHarborEnabled           *bool           `json:"is_harbor_enabled,omitempty" `
-	HarborAdminServerPassword *string         `json:"harbor_admin_server_password,omitempty"`
+	HarborAdminServerPassword *string         `json:"harbor_admin_server_password,omitempty" sensitive:"true"`
 	HarborRegistrySize        *string         `json:"harbor_registry_size,omitempty"`
 	LoadBalancerIPNum         *int64          `json:"load_balancer_ip_num,omitempty"`
 	IsIstioEnabled            *bool           `json:"is_istio_enabled,omitempty"   `
@@ -142,7 +148,7 @@ type VsphereClientConfig struct {
 	IP       *string `json:"ip,omitempty"`
 	Port     *int64  `json:"port,omitempty"`
 	Username *string `json:"username,omitempty"`
-	Password *string `json:"password,omitempty"`
+	Password *string `json:"password,omitempty" sensitive:"true"`
 }
 
 type WorkerNodePool struct {
@@ -158,10 +164,13 @@ type MasterNodePool struct {
 }
 
 func (s *Client) GetClusters() ([]Cluster, error) {
+	return s.GetClustersContext(context.Background())
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters")
+// GetClustersContext is GetClusters with a context for cancellation
+func (s *Client) GetClustersContext(ctx context.Context) ([]Cluster, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -180,10 +189,13 @@ func (s *Client) GetClusters() ([]Cluster, error) {
 }
 
 func (s *Client) GetCluster(clusterName string) (*Cluster, error) {
+	return s.GetClusterContext(context.Background(), clusterName)
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterName)
+// GetClusterContext is GetCluster with a context for cancellation
+func (s *Client) GetClusterContext(ctx context.Context, clusterName string) (*Cluster, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters/{name}", clusterName), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -201,11 +213,15 @@ func (s *Client) GetCluster(clusterName string) (*Cluster, error) {
 	return data, nil
 }
 
-func (s *Client) GetClusterHealth(clusterUUID string) (*Cluster, error) {
+// GetClusterHealth returns the health of a cluster, see ClusterHealth.Unhealthy for its failing components
+func (s *Client) GetClusterHealth(clusterUUID string) (*ClusterHealth, error) {
+	return s.GetClusterHealthContext(context.Background(), clusterUUID)
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID + "/health")
+// GetClusterHealthContext is GetClusterHealth with a context for cancellation
+func (s *Client) GetClusterHealthContext(ctx context.Context, clusterUUID string) (*ClusterHealth, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters/{uuid}/health", clusterUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +229,7 @@ func (s *Client) GetClusterHealth(clusterUUID string) (*Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	var data *Cluster
+	var data *ClusterHealth
 
 	err = json.Unmarshal(bytes, &data)
 	if err != nil {
@@ -223,11 +239,15 @@ func (s *Client) GetClusterHealth(clusterUUID string) (*Cluster, error) {
 	return data, nil
 }
 
-func (s *Client) GetClusterAuthz(clusterUUID string) (*Cluster, error) {
+// GetClusterAuthz returns the users and groups authorized on a cluster
+func (s *Client) GetClusterAuthz(clusterUUID string) (*ClusterAuthz, error) {
+	return s.GetClusterAuthzContext(context.Background(), clusterUUID)
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID + "/authz")
+// GetClusterAuthzContext is GetClusterAuthz with a context for cancellation
+func (s *Client) GetClusterAuthzContext(ctx context.Context, clusterUUID string) (*ClusterAuthz, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters/{uuid}/authz", clusterUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +255,7 @@ func (s *Client) GetClusterAuthz(clusterUUID string) (*Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	var data *Cluster
+	var data *ClusterAuthz
 
 	err = json.Unmarshal(bytes, &data)
 	if err != nil {
@@ -247,9 +267,7 @@ func (s *Client) GetClusterAuthz(clusterUUID string) (*Cluster, error) {
 
 func (s *Client) GetClusterDashboard(clusterUUID string) (*string, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID + "/dashboard")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/clusters/{uuid}/dashboard", clusterUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -264,9 +282,7 @@ func (s *Client) GetClusterDashboard(clusterUUID string) (*string, error) {
 
 func (s *Client) GetClusterEnv(clusterUUID string) (*string, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID + "/env")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/clusters/{uuid}/env", clusterUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -281,9 +297,7 @@ func (s *Client) GetClusterEnv(clusterUUID string) (*string, error) {
 
 func (s *Client) GetClusterHelmCharts(clusterUUID string) (*HelmChart, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID + "/helmcharts")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/clusters/{uuid}/helmcharts", clusterUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +317,11 @@ func (s *Client) GetClusterHelmCharts(clusterUUID string) (*HelmChart, error) {
 }
 
 func (s *Client) AddCluster(cluster *Cluster) (*Cluster, error) {
+	return s.AddClusterContext(context.Background(), cluster)
+}
+
+// AddClusterContext is AddCluster with a context for cancellation
+func (s *Client) AddClusterContext(ctx context.Context, cluster *Cluster) (*Cluster, error) {
 
 	var data Cluster
 
@@ -310,21 +329,12 @@ func (s *Client) AddCluster(cluster *Cluster) (*Cluster, error) {
 		return nil, errs
 	}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters")
-
-	j, err := json.Marshal(cluster)
-
+	req, err := s.NewRequestWithContext(ctx, "POST", endpoint("/clusters"), cluster)
 	if err != nil {
-
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(j))
-	if err != nil {
-		return nil, err
-	}
-
-	bytes, err := s.doRequest(req)
+	bytes, err := s.doMutation(req)
 
 	if err != nil {
 		return nil, err
@@ -342,6 +352,11 @@ func (s *Client) AddCluster(cluster *Cluster) (*Cluster, error) {
 }
 
 func (s *Client) AddClusterBasic(cluster *Cluster) (*Cluster, error) {
+	return s.AddClusterBasicContext(context.Background(), cluster)
+}
+
+// AddClusterBasicContext is AddClusterBasic with a context for cancellation
+func (s *Client) AddClusterBasicContext(ctx context.Context, cluster *Cluster) (*Cluster, error) {
 
 	/*
 
@@ -414,7 +429,7 @@ func (s *Client) AddClusterBasic(cluster *Cluster) (*Cluster, error) {
 
 	// Retrieve the provider client config UUID rather than have the user need to provide this themselves.
 	// This is also built for a single provider client config and as of CCP 1.5 this wll be Vsphere
-	providerClientConfigs, err := s.GetProviderClientConfigs()
+	providerClientConfigs, err := s.ListProviderClientConfigsContext(ctx, ListOptions{})
 
 	if err != nil {
 		return nil, err
@@ -463,21 +478,12 @@ func (s *Client) AddClusterBasic(cluster *Cluster) (*Cluster, error) {
 	// "Cluster level template cannot be provided when master_node_pool and worker_node_pool are provided"
 	cluster.Template = nil
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters")
-
-	j, err := json.Marshal(cluster)
-
+	req, err := s.NewRequestWithContext(ctx, "POST", endpoint("/clusters"), cluster)
 	if err != nil {
-
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(j))
-	if err != nil {
-		return nil, err
-	}
-
-	bytes, err := s.doRequest(req)
+	bytes, err := s.doMutation(req)
 
 	if err != nil {
 		return nil, err
@@ -495,6 +501,11 @@ func (s *Client) AddClusterBasic(cluster *Cluster) (*Cluster, error) {
 }
 
 func (s *Client) PatchCluster(cluster *Cluster) (*Cluster, error) {
+	return s.PatchClusterContext(context.Background(), cluster)
+}
+
+// PatchClusterContext is PatchCluster with a context for cancellation
+func (s *Client) PatchClusterContext(ctx context.Context, cluster *Cluster) (*Cluster, error) {
 
 	var data Cluster
 
@@ -504,21 +515,12 @@ func (s *Client) PatchCluster(cluster *Cluster) (*Cluster, error) {
 
 	clusterUUID := *cluster.UUID
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + clusterUUID)
-
-	j, err := json.Marshal(cluster)
-
+	req, err := s.NewRequestWithContext(ctx, "PATCH", endpoint("/clusters/{uuid}", clusterUUID), cluster)
 	if err != nil {
-
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(j))
-	if err != nil {
-		return nil, err
-	}
-
-	bytes, err := s.doRequest(req)
+	bytes, err := s.doMutation(req)
 
 	if err != nil {
 		return nil, err
@@ -536,18 +538,21 @@ func (s *Client) PatchCluster(cluster *Cluster) (*Cluster, error) {
 }
 
 func (s *Client) DeleteCluster(uuid string) error {
+	return s.DeleteClusterContext(context.Background(), uuid)
+}
+
+// DeleteClusterContext is DeleteCluster with a context for cancellation
+func (s *Client) DeleteClusterContext(ctx context.Context, uuid string) error {
 
 	if uuid == "" {
 		return errors.New("Cluster UUID to delete is required")
 	}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/clusters/" + uuid)
-
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "DELETE", endpoint("/clusters/{uuid}", uuid), nil)
 	if err != nil {
 		return err
 	}
-	_, err = s.doRequest(req)
+	_, err = s.doMutation(req)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,378 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Environment variables which override the config file
+const (
+	EnvConfig  = "CCP_CONFIG"
+	EnvContext = "CCP_CONTEXT"
+	EnvURL     = "CCP_URL"
+	EnvToken   = "CCP_TOKEN"
+)
+
+// Credential sources of a Context
+const (
+	// CredentialsEnv reads the password from CCP_PASSWORD, and the username from CCP_USERNAME when set or
+	// from the context otherwise
+	CredentialsEnv = "env"
+	// CredentialsFile, followed by a path, reads a JSON credentials file as FileCredentials does
+	CredentialsFile = "file:"
+)
+
+// ConfigFile is the configuration file shared by tools using the library, ~/.ccp/config.yaml by default.
+// It names a context for each CCP control plane
+//
+//	current-context: dev
+//	contexts:
+//	  - name: dev
+//	    url: https://ccp-dev.example.com
+//	    username: admin
+//	    credentials: env
+//	    tls:
+//	      ca-file: /etc/ccp/ca.pem
+//	    defaults:
+//	      timeout: 30s
+type ConfigFile struct {
+	CurrentContext string    `yaml:"current-context,omitempty"`
+	Contexts       []Context `yaml:"contexts"`
+}
+
+// Context is a CCP control plane and how to connect and log in to it
+type Context struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Username string `yaml:"username,omitempty"`
+	// Credentials is where the password is read from: env, the default, file:PATH, or a source added
+	// with RegisterCredentialSource such as keyring
+	Credentials string `yaml:"credentials,omitempty"`
+	// TLS are the certificates used to verify CCP and to authenticate to it
+	TLS *ContextTLS `yaml:"tls,omitempty"`
+	// Insecure skips verifying the certificate of CCP. The certificate is verified unless it is true
+	Insecure bool             `yaml:"insecure,omitempty"`
+	Defaults *ContextDefaults `yaml:"defaults,omitempty"`
+}
+
+// ContextTLS are the TLS settings of a context
+type ContextTLS struct {
+	// CAFile is a PEM file of certificates trusted in addition to the system roots
+	CAFile     string `yaml:"ca-file,omitempty"`
+	ServerName string `yaml:"server-name,omitempty"`
+	// CertFile and KeyFile are a client certificate, for appliances behind a proxy requiring one
+	CertFile string `yaml:"cert-file,omitempty"`
+	KeyFile  string `yaml:"key-file,omitempty"`
+}
+
+// ContextDefaults are the settings of clients created for a context
+type ContextDefaults struct {
+	APIVersion string        `yaml:"api-version,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	DryRun     bool          `yaml:"dry-run,omitempty"`
+}
+
+// CredentialSource creates the credentials provider of a context
+type CredentialSource func(ctx *Context) (CredentialsProvider, error)
+
+var (
+	credentialSourcesMu sync.Mutex
+	credentialSources   = map[string]CredentialSource{}
+)
+
+// RegisterCredentialSource adds a credential source which contexts can name, e.g. a "keyring" source
+// reading passwords from the system keyring. Sources are matched on the part of Context.Credentials
+// before any colon
+func RegisterCredentialSource(name string, source CredentialSource) {
+
+	credentialSourcesMu.Lock()
+	defer credentialSourcesMu.Unlock()
+
+	credentialSources[name] = source
+}
+
+// DefaultConfigPath returns the path of the config file, $CCP_CONFIG or ~/.ccp/config.yaml
+func DefaultConfigPath() string {
+
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".ccp", "config.yaml")
+	}
+
+	return filepath.Join(home, ".ccp", "config.yaml")
+}
+
+// LoadConfig reads a config file, or the DefaultConfigPath when path is empty. A missing file is
+// read as a config without contexts
+func LoadConfig(path string) (*ConfigFile, error) {
+
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ConfigFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config ConfigFile
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("Config file %s: %v", path, err)
+	}
+
+	return &config, nil
+}
+
+// Save writes the config file, or the DefaultConfigPath when path is empty, readable only by its owner
+func (c *ConfigFile) Save(path string) error {
+
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// Context returns the named context, or nil when there is none
+func (c *ConfigFile) Context(name string) *Context {
+
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+
+	return nil
+}
+
+// SetContext adds the context, or replaces the context of the same name
+func (c *ConfigFile) SetContext(ctx Context) {
+
+	if existing := c.Context(ctx.Name); existing != nil {
+		*existing = ctx
+		return
+	}
+
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// ResolveContext returns a copy of the named context with the environment overrides applied. An empty
+// name selects $CCP_CONTEXT, then the current context. CCP_URL and CCP_USERNAME replace the URL and
+// username and CCP_PASSWORD selects the env credentials, so with CCP_URL set no context is needed
+func (c *ConfigFile) ResolveContext(name string) (*Context, error) {
+
+	if name == "" {
+		name = os.Getenv(EnvContext)
+	}
+	if name == "" {
+		name = c.CurrentContext
+	}
+
+	ctx := &Context{Name: name}
+
+	if name != "" {
+		found := c.Context(name)
+		if found == nil && os.Getenv(EnvURL) == "" {
+			return nil, fmt.Errorf("Context %s not found", name)
+		}
+		if found != nil {
+			*ctx = *found
+		}
+	}
+
+	if url := os.Getenv(EnvURL); url != "" {
+		ctx.URL = url
+	}
+	if username := os.Getenv(EnvUsername); username != "" {
+		ctx.Username = username
+	}
+	if os.Getenv(EnvPassword) != "" {
+		ctx.Credentials = CredentialsEnv
+	}
+
+	if ctx.URL == "" {
+		return nil, errors.New("No CCP context is configured, add one to the config file or set CCP_URL")
+	}
+
+	return ctx, nil
+}
+
+// NewClientFromContext creates a client for a context of the DefaultConfigPath, with the environment
+// overrides of ResolveContext. An empty name selects the current context. See Context.NewClient
+func NewClientFromContext(name string) (*Client, error) {
+
+	config, err := LoadConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := config.ResolveContext(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.NewClient()
+}
+
+// NewClient creates a client for the context. When CCP_TOKEN is set the client authenticates with the
+// token, otherwise Login must still be called and reads the credentials from the context source. Unlike
+// NewClient, the certificate of CCP is verified unless the context sets insecure: true
+func (ctx *Context) NewClient() (*Client, error) {
+
+	client := &Client{BaseURL: ctx.URL}
+
+	if token := os.Getenv(EnvToken); token != "" {
+		client.Token = token
+	} else {
+		creds, err := ctx.CredentialsProvider()
+		if err != nil {
+			return nil, err
+		}
+		client.Credentials = creds
+	}
+
+	if ctx.Defaults != nil {
+		client.APIVersion = ctx.Defaults.APIVersion
+		client.DryRun = ctx.Defaults.DryRun
+	}
+
+	httpClient, err := ctx.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	client.HTTPClient = httpClient
+
+	return client, nil
+}
+
+// CredentialsProvider returns the provider of the credentials of the context
+func (ctx *Context) CredentialsProvider() (CredentialsProvider, error) {
+
+	source := ctx.Credentials
+	if source == "" {
+		source = CredentialsEnv
+	}
+
+	switch {
+	case source == CredentialsEnv:
+		return CredentialsProviderFunc(func() (*Credentials, error) {
+			password, ok := os.LookupEnv(EnvPassword)
+			if !ok {
+				return nil, fmt.Errorf("Environment variable %s is not set", EnvPassword)
+			}
+			username := os.Getenv(EnvUsername)
+			if username == "" {
+				username = ctx.Username
+			}
+			if username == "" {
+				return nil, fmt.Errorf("Environment variable %s is not set", EnvUsername)
+			}
+			return &Credentials{Username: username, Password: password}, nil
+		}), nil
+	case strings.HasPrefix(source, CredentialsFile):
+		return FileCredentials(strings.TrimPrefix(source, CredentialsFile)), nil
+	}
+
+	credentialSourcesMu.Lock()
+	registered, ok := credentialSources[strings.SplitN(source, ":", 2)[0]]
+	credentialSourcesMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("Context %s has unknown credentials source %q", ctx.Name, source)
+	}
+
+	return registered(ctx)
+}
+
+// httpClient returns an HTTP client with the TLS settings and timeout of the context
+func (ctx *Context) httpClient() (*http.Client, error) {
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: ctx.Insecure}
+
+	if t := ctx.TLS; t != nil {
+		tlsConfig.ServerName = t.ServerName
+
+		if t.CAFile != "" {
+			pem, err := ioutil.ReadFile(t.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("No certificates found in %s", t.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if t.CertFile != "" || t.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	jar, _ := cookiejar.New(nil)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Jar: jar,
+	}
+
+	if ctx.Defaults != nil {
+		client.Timeout = ctx.Defaults.Timeout
+	}
+
+	return client, nil
+}
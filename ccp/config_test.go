@@ -0,0 +1,135 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContextVerifiesTLS(t *testing.T) {
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(dir, "config.yaml")
+	config := "contexts:\n" +
+		"  - name: default\n    url: " + srv.URL + "\n" +
+		"  - name: insecure\n    url: " + srv.URL + "\n    insecure: true\n" +
+		"  - name: ca-file\n    url: " + srv.URL + "\n    tls:\n      ca-file: " + caFile + "\n" +
+		"  - name: wrong-server-name\n    url: " + srv.URL + "\n    tls:\n      ca-file: " + caFile + "\n      server-name: ccp.example.org\n"
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		context string
+		wantErr bool
+	}{
+		{context: "default", wantErr: true},
+		{context: "insecure"},
+		{context: "ca-file"},
+		{context: "wrong-server-name", wantErr: true},
+	}
+
+	for _, test := range tests {
+		ctx := loaded.Context(test.context)
+		if ctx == nil {
+			t.Fatalf("Context %s not loaded", test.context)
+		}
+
+		client, err := ctx.NewClient()
+		if err != nil {
+			t.Errorf("%s: %v", test.context, err)
+			continue
+		}
+
+		_, err = client.GetLivenessHealth()
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: request to a server with an untrusted certificate succeeded", test.context)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.context, err)
+		}
+	}
+}
+
+func TestContextEnvCredentials(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		username    string
+		envUsername string
+		envPassword string
+		want        string
+		wantErr     bool
+	}{
+		{name: "context username", username: "admin", envPassword: "secret", want: "admin"},
+		{name: "environment username", envUsername: "jsmith", envPassword: "secret", want: "jsmith"},
+		{name: "environment replaces the context", username: "admin", envUsername: "jsmith", envPassword: "secret", want: "jsmith"},
+		{name: "no username", envPassword: "secret", wantErr: true},
+		{name: "no password", username: "admin", wantErr: true},
+	}
+
+	for _, test := range tests {
+		ctx := &Context{Name: "dev", URL: "https://ccp.example.com", Username: test.username}
+
+		provider, err := ctx.CredentialsProvider()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The environment is read when the credentials are, not when the provider is created
+		t.Setenv(EnvUsername, test.envUsername)
+		t.Setenv(EnvPassword, test.envPassword)
+		if test.envPassword == "" {
+			os.Unsetenv(EnvPassword)
+		}
+
+		creds, err := provider.Credentials()
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: returned %+v, want an error", test.name, creds)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case !test.wantErr && (creds.Username != test.want || creds.Password != test.envPassword):
+			t.Errorf("%s: returned %s, want %s", test.name, creds.Username, test.want)
+		}
+	}
+}
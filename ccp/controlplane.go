@@ -0,0 +1,245 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Verdicts of CheckControlPlane
+const (
+	ControlPlaneHealthy  = "healthy"
+	ControlPlaneDegraded = "degraded"
+	ControlPlaneDown     = "down"
+)
+
+// MaxClockSkew is how far the clock of the management host may be from the local clock before the
+// control plane is degraded. Skew breaks token expiry and certificate validity checks
+const MaxClockSkew = time.Minute
+
+// ControlPlaneStatus is the verdict of CheckControlPlane and the reasons for it
+type ControlPlaneStatus struct {
+	// Verdict is ControlPlaneDown when the liveness check fails, ControlPlaneDegraded when it passes but
+	// the health check fails or finds a problem, and ControlPlaneHealthy otherwise
+	Verdict string
+	// Reasons describe each problem found, e.g. "Pod cx-api-0 is not Ready"
+	Reasons []string
+	// Components are the failing components found by the health check, see Health.Unhealthy
+	Components []HealthComponent
+
+	// Version is the CXVersion reported by the liveness check
+	Version string
+	// ClockSkew is how far the clock of the management host is ahead of the local clock, or behind it
+	// when negative. It is nil when the time of the management host is unknown
+	ClockSkew *time.Duration
+
+	CheckedAt time.Time
+}
+
+// Ready reports whether the control plane is up, even if degraded
+func (c *ControlPlaneStatus) Ready() bool {
+	return c.Verdict != ControlPlaneDown
+}
+
+func (c *ControlPlaneStatus) degrade(reason string) {
+
+	if c.Verdict == ControlPlaneHealthy {
+		c.Verdict = ControlPlaneDegraded
+	}
+	c.Reasons = append(c.Reasons, reason)
+}
+
+// CheckControlPlane combines the liveness and health checks of the CCP control plane into a verdict.
+// The control plane is degraded by missing nodes, failing nodes and pods, and a clock on the
+// management host more than MaxClockSkew from the local clock. Failed requests are reasons, not errors
+func (s *Client) CheckControlPlane(ctx context.Context) *ControlPlaneStatus {
+
+	status := &ControlPlaneStatus{Verdict: ControlPlaneHealthy, Reasons: []string{}, CheckedAt: time.Now()}
+
+	sent := time.Now()
+	liveness, err := s.GetLivenessHealthContext(ctx)
+	received := time.Now()
+
+	if err != nil {
+		status.Verdict = ControlPlaneDown
+		status.Reasons = append(status.Reasons, fmt.Sprintf("Liveness check failed: %v", err))
+		return status
+	}
+
+	status.Version = derefString(liveness.CXVersion)
+
+	if liveness.TimeOnMgmtHost != nil {
+		host, err := parseHostTime(*liveness.TimeOnMgmtHost)
+		if err != nil {
+			status.degrade(fmt.Sprintf("Time on the management host %q is not valid", *liveness.TimeOnMgmtHost))
+		} else {
+			// The host read its clock somewhere between sending and receiving, so the midpoint is the
+			// best guess of the local time it corresponds to
+			skew := host.Sub(sent.Add(received.Sub(sent) / 2))
+			status.ClockSkew = &skew
+			if skew > MaxClockSkew {
+				status.degrade(fmt.Sprintf("Clock of the management host is %s ahead", skew.Round(time.Second)))
+			} else if skew < -MaxClockSkew {
+				status.degrade(fmt.Sprintf("Clock of the management host is %s behind", (-skew).Round(time.Second)))
+			}
+		}
+	}
+
+	health, err := s.GetHealthContext(ctx)
+	if err != nil {
+		status.degrade(fmt.Sprintf("Health check failed: %v", err))
+		return status
+	}
+
+	status.Components = health.Unhealthy()
+	for _, component := range status.Components {
+		status.degrade(component.Reason)
+	}
+
+	return status
+}
+
+// hostTimeLayouts are the formats TimeOnMgmtHost is parsed with, RFC 3339 and that of time.Time.String
+var hostTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.UnixDate,
+}
+
+func parseHostTime(value string) (time.Time, error) {
+
+	var err error
+
+	for _, layout := range hostTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// DefaultReadinessCacheTTL is how long a ReadinessHandler reuses a check when no TTL is given
+const DefaultReadinessCacheTTL = 5 * time.Second
+
+// ReadinessOptions configure ReadinessHandler
+type ReadinessOptions struct {
+	// CacheTTL is how long the result of a check answers requests before the control plane is checked
+	// again, DefaultReadinessCacheTTL when not set. A negative value checks on every request
+	CacheTTL time.Duration
+
+	// Logger, when set, receives the reasons for each check which is not healthy. It defaults to the
+	// Logger of the client when the SystemService is a *Client
+	Logger Logger
+}
+
+// readiness is the body of a ReadinessHandler response
+type readiness struct {
+	Status    string `json:"status"`
+	CheckedAt string `json:"checked_at"`
+}
+
+// readinessCheck runs CheckControlPlane for a ReadinessHandler, reusing the last result until it expires
+type readinessCheck struct {
+	system SystemService
+	ttl    time.Duration
+	logger Logger
+
+	mu      sync.Mutex
+	last    *ControlPlaneStatus
+	expires time.Time
+}
+
+func (c *readinessCheck) status(ctx context.Context) *ControlPlaneStatus {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Now().Before(c.expires) {
+		return c.last
+	}
+
+	status := c.system.CheckControlPlane(ctx)
+
+	logger := c.logger
+	if client, ok := c.system.(*Client); ok && logger == nil {
+		logger = client.Logger
+	}
+
+	if logger != nil && status.Verdict != ControlPlaneHealthy {
+		logger.Error("CCP control plane is "+status.Verdict,
+			"reasons", status.Reasons,
+			"version", status.Version,
+		)
+	}
+
+	// A check cut short by the request going away says nothing about the control plane
+	if ctx.Err() == nil {
+		c.last = status
+		c.expires = time.Now().Add(c.ttl)
+	}
+
+	return status
+}
+
+// ReadinessHandler returns a handler which runs CheckControlPlane and responds with the verdict as JSON.
+// The status is 200 when the control plane is healthy or degraded and 503 when it is down, so it can back
+// a readiness probe or an external monitor. The reasons are logged rather than returned, so the endpoint
+// can be exposed without describing the control plane, and each check is reused for opts.CacheTTL so
+// frequent probes do not load CCP
+func ReadinessHandler(system SystemService, opts ReadinessOptions) http.Handler {
+
+	check := &readinessCheck{system: system, ttl: opts.CacheTTL, logger: opts.Logger}
+
+	if check.ttl == 0 {
+		check.ttl = DefaultReadinessCacheTTL
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		status := check.status(r.Context())
+
+		body := readiness{
+			Status:    status.Verdict,
+			CheckedAt: status.CheckedAt.UTC().Format(time.RFC3339),
+		}
+
+		code := http.StatusOK
+		if !status.Ready() {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(body)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckControlPlane(t *testing.T) {
+
+	healthy := `{"TotalSystemHealth": "Healthy", "CurrentNodes": 3, "ExpectedNodes": 3}`
+	hostTime := func(offset time.Duration) string {
+		return `{"CXVersion": "1.5.0", "TimeOnMgmtHost": "` + time.Now().Add(offset).Format(time.RFC3339Nano) + `"}`
+	}
+
+	tests := []struct {
+		name string
+		// liveness and health are the response bodies, or an error status when empty
+		liveness string
+		health   string
+		verdict  string
+		reasons  []string
+		// skew is whether a clock skew is reported
+		skew bool
+	}{
+		{name: "healthy", liveness: hostTime(0), health: healthy, verdict: ControlPlaneHealthy, reasons: []string{}, skew: true},
+		{name: "liveness failed", health: healthy, verdict: ControlPlaneDown, reasons: []string{"Liveness check failed: Service unavailable"}},
+		{name: "health failed", liveness: hostTime(0), verdict: ControlPlaneDegraded, reasons: []string{"Health check failed: Service unavailable"}, skew: true},
+		{
+			name:     "missing nodes",
+			liveness: hostTime(0),
+			health:   `{"TotalSystemHealth": "Healthy", "CurrentNodes": 2, "ExpectedNodes": 3}`,
+			verdict:  ControlPlaneDegraded,
+			reasons:  []string{"Only 2 of 3 expected nodes are up"},
+			skew:     true,
+		},
+		{
+			name:     "failing pod",
+			liveness: hostTime(0),
+			health:   `{"TotalSystemHealth": "Unhealthy", "PodStatusList": [{"PodName": "cx-api-0", "PodCondition": "Ready", "PodStatus": "False"}]}`,
+			verdict:  ControlPlaneDegraded,
+			reasons:  []string{"Health is Unhealthy", "Pod cx-api-0 is not Ready"},
+			skew:     true,
+		},
+		{
+			name:     "clock ahead",
+			liveness: hostTime(5 * time.Minute),
+			health:   healthy,
+			verdict:  ControlPlaneDegraded,
+			reasons:  []string{"Clock of the management host is 5m0s ahead"},
+			skew:     true,
+		},
+		{
+			name:     "clock behind",
+			liveness: hostTime(-2 * time.Minute),
+			health:   healthy,
+			verdict:  ControlPlaneDegraded,
+			reasons:  []string{"Clock of the management host is 2m0s behind"},
+			skew:     true,
+		},
+		{name: "clock within the limit", liveness: hostTime(30 * time.Second), health: healthy, verdict: ControlPlaneHealthy, reasons: []string{}, skew: true},
+		{
+			name:     "invalid host time",
+			liveness: `{"CXVersion": "1.5.0", "TimeOnMgmtHost": "yesterday"}`,
+			health:   healthy,
+			verdict:  ControlPlaneDegraded,
+			reasons:  []string{`Time on the management host "yesterday" is not valid`},
+		},
+		{name: "no host time", liveness: `{"CXVersion": "1.5.0"}`, health: healthy, verdict: ControlPlaneHealthy, reasons: []string{}},
+	}
+
+	for _, test := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := map[string]string{
+				"/2/system/livenessHealth": test.liveness,
+				"/2/system/health":         test.health,
+			}[r.URL.Path]
+			if body == "" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Service unavailable"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+
+		status := NewClient("admin", "secret", srv.URL).CheckControlPlane(context.Background())
+
+		srv.Close()
+
+		if status.Verdict != test.verdict {
+			t.Errorf("%s: verdict is %s, want %s", test.name, status.Verdict, test.verdict)
+		}
+		if !reflect.DeepEqual(status.Reasons, test.reasons) {
+			t.Errorf("%s: reasons are %q, want %q", test.name, status.Reasons, test.reasons)
+		}
+		if (status.ClockSkew != nil) != test.skew {
+			t.Errorf("%s: clock skew is %v, want a skew %v", test.name, status.ClockSkew, test.skew)
+		}
+		if status.Verdict != ControlPlaneDown && status.Version != "1.5.0" {
+			t.Errorf("%s: version is %q, want 1.5.0", test.name, status.Version)
+		}
+	}
+}
+
+// checkingSystem is a SystemService which counts its control plane checks. Its other methods are not
+// used by a ReadinessHandler
+type checkingSystem struct {
+	SystemService
+	status *ControlPlaneStatus
+	checks int
+}
+
+func (s *checkingSystem) CheckControlPlane(ctx context.Context) *ControlPlaneStatus {
+
+	s.checks++
+
+	return s.status
+}
+
+func TestReadinessHandler(t *testing.T) {
+
+	checkedAt := time.Date(2019, 3, 4, 10, 11, 12, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		method    string
+		verdict   string
+		code      int
+		body      string
+		logged    bool
+		wantAllow bool
+	}{
+		{name: "healthy", method: "GET", verdict: ControlPlaneHealthy, code: http.StatusOK, body: `{"status":"healthy","checked_at":"2019-03-04T10:11:12Z"}`},
+		{name: "degraded", method: "GET", verdict: ControlPlaneDegraded, code: http.StatusOK, body: `{"status":"degraded","checked_at":"2019-03-04T10:11:12Z"}`, logged: true},
+		{name: "down", method: "GET", verdict: ControlPlaneDown, code: http.StatusServiceUnavailable, body: `{"status":"down","checked_at":"2019-03-04T10:11:12Z"}`, logged: true},
+		{name: "head", method: "HEAD", verdict: ControlPlaneDown, code: http.StatusServiceUnavailable, logged: true},
+		{name: "post", method: "POST", verdict: ControlPlaneHealthy, code: http.StatusMethodNotAllowed, body: "Method not allowed", wantAllow: true},
+	}
+
+	for _, test := range tests {
+		system := &checkingSystem{status: &ControlPlaneStatus{
+			Verdict:   test.verdict,
+			Reasons:   []string{"Pod cx-api-0 is not Ready"},
+			Version:   "1.5.0",
+			CheckedAt: checkedAt,
+		}}
+		logger := &recordingLogger{}
+
+		rec := httptest.NewRecorder()
+		ReadinessHandler(system, ReadinessOptions{Logger: logger}).ServeHTTP(rec, httptest.NewRequest(test.method, "/readyz", nil))
+
+		if rec.Code != test.code {
+			t.Errorf("%s: status %d, want %d", test.name, rec.Code, test.code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != test.body {
+			t.Errorf("%s: body %s, want %s", test.name, body, test.body)
+		}
+		if test.wantAllow && rec.Header().Get("Allow") != "GET, HEAD" {
+			t.Errorf("%s: Allow is %q", test.name, rec.Header().Get("Allow"))
+		}
+
+		// The reasons are logged, never returned
+		if strings.Contains(rec.Body.String(), "cx-api-0") {
+			t.Errorf("%s: body %s holds the reasons", test.name, rec.Body.String())
+		}
+		if logged := len(logger.records) == 1 && strings.Contains(logger.records[0], "cx-api-0"); logged != test.logged {
+			t.Errorf("%s: logged %q, want the reasons logged %v", test.name, logger.records, test.logged)
+		}
+	}
+}
+
+func TestReadinessHandlerCache(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		requests int
+		// cancelled is set to send the requests with a cancelled context
+		cancelled bool
+		checks    int
+	}{
+		{name: "default", requests: 3, checks: 1},
+		{name: "cached", ttl: time.Hour, requests: 3, checks: 1},
+		{name: "expired", ttl: time.Nanosecond, requests: 3, checks: 3},
+		{name: "disabled", ttl: -1, requests: 3, checks: 3},
+		{name: "cancelled requests not cached", ttl: time.Hour, requests: 3, cancelled: true, checks: 3},
+	}
+
+	for _, test := range tests {
+		system := &checkingSystem{status: &ControlPlaneStatus{Verdict: ControlPlaneDegraded, Reasons: []string{"Health check failed"}}}
+		logger := &recordingLogger{}
+
+		handler := ReadinessHandler(system, ReadinessOptions{CacheTTL: test.ttl, Logger: logger})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if test.cancelled {
+			cancel()
+		}
+
+		for i := 0; i < test.requests; i++ {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil).WithContext(ctx))
+
+			var body readiness
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Status != ControlPlaneDegraded {
+				t.Errorf("%s: request %d returned %s", test.name, i, rec.Body.String())
+			}
+		}
+
+		cancel()
+
+		if system.checks != test.checks {
+			t.Errorf("%s: checked the control plane %d times, want %d", test.name, system.checks, test.checks)
+		}
+		// Only the checks made are logged, not every request answered from the cache
+		if len(logger.records) != test.checks {
+			t.Errorf("%s: logged %d records, want %d", test.name, len(logger.records), test.checks)
+		}
+	}
+}
+
+func TestReadinessHandlerUsesClientLogger(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient("admin", "secret", srv.URL)
+	handler := ReadinessHandler(client, ReadinessOptions{})
+
+	// The logger is set after the handler is created
+	logger := &recordingLogger{}
+	client.Logger = logger
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status %d, want 503", rec.Code)
+	}
+
+	found := false
+	for _, record := range logger.records {
+		if strings.HasPrefix(record, "CCP control plane is down") && strings.Contains(record, "Liveness check failed") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Records %q do not hold the reasons the control plane is down", logger.records)
+	}
+}
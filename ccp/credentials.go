@@ -0,0 +1,167 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables read by EnvCredentials when no other names are given
+const (
+	EnvUsername = "CCP_USERNAME"
+	EnvPassword = "CCP_PASSWORD"
+)
+
+// redacted replaces secrets in errors and printed values
+const redacted = "[REDACTED]"
+
+// Credentials are the username and password used to log in to CCP
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password" sensitive:"true"`
+}
+
+// String prints the credentials without the password
+func (c Credentials) String() string {
+	return fmt.Sprintf("{Username:%s Password:%s}", c.Username, redacted)
+}
+
+// GoString prints the credentials without the password
+func (c Credentials) GoString() string {
+	return fmt.Sprintf("ccp.Credentials{Username:%q, Password:%q}", c.Username, redacted)
+}
+
+// CredentialsProvider supplies the credentials used by Login. It is called on every login so that
+// rotated passwords are picked up without creating a new Client
+type CredentialsProvider interface {
+	Credentials() (*Credentials, error)
+}
+
+// CredentialsProviderFunc allows a function to be used as a CredentialsProvider
+type CredentialsProviderFunc func() (*Credentials, error)
+
+func (f CredentialsProviderFunc) Credentials() (*Credentials, error) {
+	return f()
+}
+
+// StaticCredentials returns a provider which always supplies the given username and password
+func StaticCredentials(username, password string) CredentialsProvider {
+	return CredentialsProviderFunc(func() (*Credentials, error) {
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// EnvCredentials returns a provider which reads the username and password from the named environment
+// variables. Empty names default to CCP_USERNAME and CCP_PASSWORD
+func EnvCredentials(usernameVar, passwordVar string) CredentialsProvider {
+
+	if usernameVar == "" {
+		usernameVar = EnvUsername
+	}
+	if passwordVar == "" {
+		passwordVar = EnvPassword
+	}
+
+	return CredentialsProviderFunc(func() (*Credentials, error) {
+
+		username, ok := os.LookupEnv(usernameVar)
+		if !ok || username == "" {
+			return nil, fmt.Errorf("Environment variable %s is not set", usernameVar)
+		}
+		password, ok := os.LookupEnv(passwordVar)
+		if !ok {
+			return nil, fmt.Errorf("Environment variable %s is not set", passwordVar)
+		}
+
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// FileCredentials returns a provider which reads the credentials from a JSON file of the form
+// {"username": "admin", "password": "secret"}. The file is read on every login
+func FileCredentials(path string) CredentialsProvider {
+
+	return CredentialsProviderFunc(func() (*Credentials, error) {
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var creds Credentials
+
+		// The decoder error is not returned as is since it may quote part of the file
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return nil, fmt.Errorf("Credentials file %s is not valid JSON", path)
+		}
+		if creds.Username == "" {
+			return nil, fmt.Errorf("Credentials file %s does not contain a username", path)
+		}
+
+		return &creds, nil
+	})
+}
+
+// credentials returns the credentials for the client, preferring the Credentials provider over the
+// Username and Password fields
+func (s *Client) credentials() (*Credentials, error) {
+
+	if s.Credentials == nil {
+		if s.Username == "" {
+			return nil, errors.New("Client.Username is missing")
+		}
+		return &Credentials{Username: s.Username, Password: s.Password}, nil
+	}
+
+	creds, err := s.Credentials.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil || creds.Username == "" {
+		return nil, errors.New("Credentials provider did not return a username")
+	}
+
+	return creds, nil
+}
+
+// scrubSecret removes every occurrence of secret, plain or form encoded, from the error message. An
+// *APIError is returned as an *APIError with the secret removed from its body
+func scrubSecret(err error, secret string) error {
+
+	if err == nil || secret == "" {
+		return err
+	}
+
+	msg := err.Error()
+	scrubbed := strings.Replace(msg, secret, redacted, -1)
+	scrubbed = strings.Replace(scrubbed, url.QueryEscape(secret), redacted, -1)
+
+	if scrubbed == msg {
+		return err
+	}
+
+	if apiErr, ok := err.(*APIError); ok {
+		return &APIError{StatusCode: apiErr.StatusCode, Body: []byte(scrubbed)}
+	}
+
+	return errors.New(scrubbed)
+}
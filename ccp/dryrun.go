@@ -0,0 +1,110 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context which makes methods taking a context, such as AddClusterContext,
+// DeleteUserContext and ApplyCluster, behave as though Client.DryRun was set
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// PlannedRequest is a request which would have been sent to CCP. Sensitive values in the URL and body
+// are redacted
+type PlannedRequest struct {
+	Method string
+	URL    string
+	Body   json.RawMessage
+}
+
+func (r *PlannedRequest) String() string {
+
+	s := r.Method + " " + r.URL
+
+	if len(r.Body) > 0 {
+		var indented bytes.Buffer
+		if json.Indent(&indented, r.Body, "", "  ") == nil {
+			s += "\n" + indented.String()
+		}
+	}
+
+	return s
+}
+
+// DryRunError is returned by a method which would have changed CCP when dry run is enabled. All
+// validation and defaulting has been done and Request holds what would have been sent
+type DryRunError struct {
+	Request *PlannedRequest
+}
+
+func (e *DryRunError) Error() string {
+	return "Dry run, request not sent: " + e.Request.Method + " " + e.Request.URL
+}
+
+// DryRunRequest returns the request planned by a method that returned err during a dry run
+func DryRunRequest(err error) (*PlannedRequest, bool) {
+
+	dryRun, ok := err.(*DryRunError)
+	if !ok {
+		return nil, false
+	}
+
+	return dryRun.Request, true
+}
+
+func (s *Client) dryRun(ctx context.Context) bool {
+
+	if s.DryRun {
+		return true
+	}
+
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+
+	return dryRun
+}
+
+// doMutation sends a request which changes CCP, unless dry run is enabled in which case the request is
+// returned in a DryRunError
+func (s *Client) doMutation(req *http.Request) ([]byte, error) {
+
+	if !s.dryRun(req.Context()) {
+		return s.doRequest(req)
+	}
+
+	planned := &PlannedRequest{
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+	}
+
+	body, err := RequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > 0 {
+		planned.Body = RedactJSON(body)
+	}
+
+	return nil, &DryRunError{Request: planned}
+}
@@ -0,0 +1,214 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+type goldenCase struct {
+	// name of the method, which is also the name of the golden file
+	name string
+	call func(c *Client, v goldenVars) (interface{}, error)
+	// model, when set, is decoded from the last response for the round trip check in place of the
+	// value returned by call
+	model interface{}
+}
+
+func result(v interface{}, err error) (interface{}, error) {
+	return v, err
+}
+
+// goldenCases exercise every method of API. They are recorded in this order, so objects are deleted
+// only after every other case using them
+var goldenCases = []goldenCase{
+	{name: "Login", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return nil, c.Login(nil)
+	}},
+	{name: "GetLivenessHealth", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetLivenessHealth())
+	}},
+	{name: "GetHealth", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetHealth())
+	}},
+	{name: "GetRole", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetRole())
+	}},
+	{name: "GetLDAPSetup", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetLDAPSetup())
+	}},
+	{name: "GetACIProfiles", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetACIProfiles())
+	}},
+
+	{name: "AddUser", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.AddUser(&User{
+			Username:  String(v.Username),
+			Password:  String("Cisco123!"),
+			Role:      String("Developer"),
+			FirstName: String("Jane"),
+			LastName:  String("Doe"),
+			Disable:   Bool(false),
+		}))
+	}},
+	{name: "GetUsers", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetUsers())
+	}},
+	// GetUser filters the list of users, so the whole list is checked
+	{name: "GetUser", model: &[]User{}, call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetUser(v.Username))
+	}},
+	{name: "PatchUser", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.PatchUser(&User{
+			Username: String(v.Username),
+			LastName: String("Smith"),
+		}))
+	}},
+	{name: "DeleteUser", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return nil, c.DeleteUser(v.Username)
+	}},
+
+	{name: "GetProviderClientConfigs", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigs())
+	}},
+	{name: "GetProviderClientConfig", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfig(v.ProviderUUID))
+	}},
+	{name: "GetProviderClientConfigClusters", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigClusters(v.ProviderUUID))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenter", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenter(v.ProviderUUID))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenterClusters", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenterClusters(v.ProviderUUID, v.Datacenter))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenterVMs", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenterVMs(v.ProviderUUID, v.Datacenter))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenterNetworks", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenterNetworks(v.ProviderUUID, v.Datacenter))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenterDatastores", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenterDatastores(v.ProviderUUID, v.Datacenter))
+	}},
+	{name: "GetProviderClientConfigVsphereDatacenterClusterPools", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetProviderClientConfigVsphereDatacenterClusterPools(v.ProviderUUID, v.Datacenter, v.VsphereCluster))
+	}},
+
+	{name: "GetClusters", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusters())
+	}},
+	{name: "GetCluster", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetCluster(v.ClusterName))
+	}},
+	{name: "GetClusterHealth", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusterHealth(v.ClusterUUID))
+	}},
+	{name: "GetClusterAuthz", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusterAuthz(v.ClusterUUID))
+	}},
+	{name: "GetClusterDashboard", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusterDashboard(v.ClusterUUID))
+	}},
+	{name: "GetClusterEnv", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusterEnv(v.ClusterUUID))
+	}},
+	{name: "GetClusterHelmCharts", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.GetClusterHelmCharts(v.ClusterUUID))
+	}},
+	{name: "AddCluster", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.AddCluster(goldenCluster(v, v.ClusterName+"-add")))
+	}},
+	{name: "AddClusterBasic", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.AddClusterBasic(&Cluster{
+			Name:            String(v.ClusterName + "-basic"),
+			Datacenter:      String(v.Datacenter),
+			Cluster:         String(v.VsphereCluster),
+			ResourcePool:    String(v.VsphereCluster + "/Resources"),
+			Datastore:       String("datastore1"),
+			Networks:        &[]string{"ccp-network/ccp-network-port-group"},
+			SSHUser:         String("ccp"),
+			SSHKey:          String("ssh-rsa AAAAB3NzaC1yc2E ccp@example.com"),
+			Workers:         Int64(1),
+			Masters:         Int64(1),
+			IsHarborEnabled: Bool(false),
+			IsIstioEnabled:  Bool(false),
+			Template:        String("ccp-tenant-image-1.10.1-ubuntu16-1.5.0"),
+		}))
+	}},
+	{name: "PatchCluster", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return result(c.PatchCluster(&Cluster{
+			UUID:    String(v.ClusterUUID),
+			Workers: Int64(2),
+		}))
+	}},
+	{name: "DeleteCluster", call: func(c *Client, v goldenVars) (interface{}, error) {
+		return nil, c.DeleteCluster(v.ClusterUUID)
+	}},
+}
+
+// goldenCluster returns a cluster with every field required by AddCluster
+func goldenCluster(v goldenVars, name string) *Cluster {
+
+	return &Cluster{
+		Name:                     String(name),
+		ProviderClientConfigUUID: String(v.ProviderUUID),
+		KubernetesVersion:        String("1.10.1"),
+		Description:              String("Golden file test cluster"),
+		Datacenter:               String(v.Datacenter),
+		Cluster:                  String(v.VsphereCluster),
+		ResourcePool:             String(v.VsphereCluster + "/Resources"),
+		Datastore:                String("datastore1"),
+		Networks:                 &[]string{"ccp-network/ccp-network-port-group"},
+		SSHUser:                  String("ccp"),
+		SSHKey:                   String("ssh-rsa AAAAB3NzaC1yc2E ccp@example.com"),
+		Workers:                  Int64(1),
+		Masters:                  Int64(1),
+		VCPUs:                    Int64(2),
+		Memory:                   Int64(16384),
+		Type:                     Int64(1),
+		IsHarborEnabled:          Bool(false),
+		IsIstioEnabled:           Bool(false),
+		NetworkPlugin: &NetworkPlugin{
+			Name:    String("contiv-vpp"),
+			Status:  String(""),
+			Details: String("{\"pod_cidr\":\"192.168.0.0/16\"}"),
+		},
+		Deployer: &Deployer{
+			ProviderType: String("vsphere"),
+			Provider: &Provider{
+				VsphereDataCenter:       String(v.Datacenter),
+				VsphereDatastore:        String("datastore1"),
+				VsphereClientConfigUUID: String(v.ProviderUUID),
+				VsphereWorkingDir:       String("/" + v.Datacenter + "/vm"),
+			},
+		},
+		WorkerNodePool: &WorkerNodePool{
+			VCPUs:    Int64(2),
+			Memory:   Int64(16384),
+			Template: String("ccp-tenant-image-1.10.1-ubuntu16-1.5.0"),
+		},
+		MasterNodePool: &MasterNodePool{
+			VCPUs:    Int64(2),
+			Memory:   Int64(8192),
+			Template: String("ccp-tenant-image-1.10.1-ubuntu16-1.5.0"),
+		},
+		Infra: &Infra{
+			Datacenter:   String(v.Datacenter),
+			Datastore:    String("datastore1"),
+			Cluster:      String(v.VsphereCluster),
+			Networks:     &[]string{"ccp-network/ccp-network-port-group"},
+			ResourcePool: String(v.VsphereCluster + "/Resources"),
+		},
+	}
+}
@@ -0,0 +1,183 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fleet lists the clusters of several CCP instances at once. Each client must be logged in, or have
+// a Token, before it is used. Any ClusterService can be added, such as a *Client or a ccpmock.Client
+type Fleet struct {
+	Instances []FleetInstance
+}
+
+// FleetInstance is a CCP instance of a fleet and the client used to reach it
+type FleetInstance struct {
+	Name   string
+	Client ClusterService
+}
+
+// FleetCluster is a cluster tagged with the name of the instance it was listed from
+type FleetCluster struct {
+	Instance string `json:"instance"`
+	Cluster
+}
+
+// FleetFilter selects clusters from a fleet. Each field, when set, must match for a cluster to be
+// selected
+type FleetFilter struct {
+	// States selects clusters in any of the states, e.g. StateReady
+	States []string
+	// KubernetesVersions selects clusters running any of the versions. A version also matches its
+	// patch releases, so 1.10 matches 1.10.1
+	KubernetesVersions []string
+	// Labels selects clusters whose labels match the selector, see ParseLabelSelector
+	Labels *LabelSelector
+	// ProviderClientConfigUUIDs selects clusters deployed with any of the provider client configs
+	ProviderClientConfigUUIDs []string
+}
+
+// FleetError holds the errors of the instances which could not be listed
+type FleetError struct {
+	Errors map[string]error
+}
+
+func (e *FleetError) Error() string {
+
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = fmt.Sprintf("%s: %v", name, e.Errors[name])
+	}
+
+	return fmt.Sprintf("%d of the fleet instances failed: %s", len(names), strings.Join(messages, "; "))
+}
+
+// NewFleet creates an empty fleet
+func NewFleet() *Fleet {
+	return &Fleet{}
+}
+
+// Add adds an instance to the fleet. Its name tags the clusters listed from it and must be unique, as
+// GetClusters rejects a fleet with two instances of the same name
+func (f *Fleet) Add(name string, client ClusterService) *Fleet {
+
+	f.Instances = append(f.Instances, FleetInstance{Name: name, Client: client})
+
+	return f
+}
+
+// GetClusters lists the clusters of every instance concurrently and returns those selected by filter,
+// or every cluster when filter is nil. Clusters are returned in the order of the instances. When some
+// instances fail the clusters of the others are still returned, along with a *FleetError
+func (f *Fleet) GetClusters(filter *FleetFilter) ([]FleetCluster, error) {
+	return f.GetClustersContext(context.Background(), filter)
+}
+
+// GetClustersContext is GetClusters with a context for cancellation
+func (f *Fleet) GetClustersContext(ctx context.Context, filter *FleetFilter) ([]FleetCluster, error) {
+
+	// The clusters and errors of each instance are keyed by its name
+	names := map[string]bool{}
+	for _, instance := range f.Instances {
+		if names[instance.Name] {
+			return nil, fmt.Errorf("Fleet instance name %s is used more than once", instance.Name)
+		}
+		names[instance.Name] = true
+	}
+
+	results := make([][]Cluster, len(f.Instances))
+	errs := make([]error, len(f.Instances))
+
+	var wg sync.WaitGroup
+
+	for i, instance := range f.Instances {
+		wg.Add(1)
+		go func(i int, instance FleetInstance) {
+			defer wg.Done()
+			if instance.Client == nil {
+				errs[i] = fmt.Errorf("Instance %s has no client", instance.Name)
+				return
+			}
+			results[i], errs[i] = instance.Client.GetClustersContext(ctx)
+		}(i, instance)
+	}
+
+	wg.Wait()
+
+	var clusters []FleetCluster
+	failed := map[string]error{}
+
+	for i, instance := range f.Instances {
+		if errs[i] != nil {
+			failed[instance.Name] = errs[i]
+			continue
+		}
+		for _, cluster := range results[i] {
+			if filter.Match(&cluster) {
+				clusters = append(clusters, FleetCluster{Instance: instance.Name, Cluster: cluster})
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return clusters, &FleetError{Errors: failed}
+	}
+
+	return clusters, nil
+}
+
+// Match reports whether the filter selects the cluster. A nil filter selects every cluster
+func (filter *FleetFilter) Match(cluster *Cluster) bool {
+
+	if filter == nil {
+		return true
+	}
+
+	if len(filter.States) > 0 && !containsString(filter.States, derefString(cluster.State)) {
+		return false
+	}
+
+	if len(filter.ProviderClientConfigUUIDs) > 0 && !containsString(filter.ProviderClientConfigUUIDs, derefString(cluster.ProviderClientConfigUUID)) {
+		return false
+	}
+
+	if len(filter.KubernetesVersions) > 0 {
+		version := derefString(cluster.KubernetesVersion)
+		matched := false
+		for _, want := range filter.KubernetesVersions {
+			if version == want || strings.HasPrefix(version, want+".") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return filter.Labels.Matches(ClusterLabels(cluster))
+}
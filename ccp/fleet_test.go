@@ -0,0 +1,169 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fleetInstance is a ClusterService listing a fixed set of clusters. Its other methods are not used
+// by a fleet
+type fleetInstance struct {
+	ClusterService
+	clusters []Cluster
+	err      error
+}
+
+func (f *fleetInstance) GetClustersContext(ctx context.Context) ([]Cluster, error) {
+	return f.clusters, f.err
+}
+
+func fleetCluster(name, state, version, provider string, labels ...string) Cluster {
+
+	return Cluster{
+		Name:                     String(name),
+		State:                    String(state),
+		KubernetesVersion:        String(version),
+		ProviderClientConfigUUID: String(provider),
+		Labels:                   labelList(labels...),
+	}
+}
+
+func TestFleetFilter(t *testing.T) {
+
+	fleet := NewFleet().
+		Add("dc1", &fleetInstance{clusters: []Cluster{
+			fleetCluster("web", StateReady, "1.10.1", "vsphere-1", "env", "prod", "team", "web"),
+			fleetCluster("batch", StateError, "1.11.0", "vsphere-1", "env", "dev"),
+		}}).
+		Add("dc2", &fleetInstance{clusters: []Cluster{
+			fleetCluster("web", StateCreating, "1.10.3", "vsphere-2", "env", "prod"),
+			fleetCluster("gpu", StateReady, "1.100.0", "vsphere-2", "gpu", "true"),
+		}})
+
+	selector := func(s string) *LabelSelector {
+		sel, err := ParseLabelSelector(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sel
+	}
+
+	tests := []struct {
+		name   string
+		filter *FleetFilter
+		want   []string
+	}{
+		{name: "nil filter", want: []string{"dc1/web", "dc1/batch", "dc2/web", "dc2/gpu"}},
+		{name: "empty filter", filter: &FleetFilter{}, want: []string{"dc1/web", "dc1/batch", "dc2/web", "dc2/gpu"}},
+		{name: "states", filter: &FleetFilter{States: []string{StateReady, StateCreating}}, want: []string{"dc1/web", "dc2/web", "dc2/gpu"}},
+		{name: "version prefix", filter: &FleetFilter{KubernetesVersions: []string{"1.10"}}, want: []string{"dc1/web", "dc2/web"}},
+		{name: "exact version", filter: &FleetFilter{KubernetesVersions: []string{"1.11.0", "1.100.0"}}, want: []string{"dc1/batch", "dc2/gpu"}},
+		{name: "provider", filter: &FleetFilter{ProviderClientConfigUUIDs: []string{"vsphere-2"}}, want: []string{"dc2/web", "dc2/gpu"}},
+		{name: "label", filter: &FleetFilter{Labels: selector("env=prod")}, want: []string{"dc1/web", "dc2/web"}},
+		{name: "label set", filter: &FleetFilter{Labels: selector("env in (dev,prod),team!=web")}, want: []string{"dc1/batch", "dc2/web"}},
+		{name: "label exists", filter: &FleetFilter{Labels: selector("!env")}, want: []string{"dc2/gpu"}},
+		{
+			name:   "every field",
+			filter: &FleetFilter{States: []string{StateReady}, KubernetesVersions: []string{"1.10"}, Labels: selector("team"), ProviderClientConfigUUIDs: []string{"vsphere-1"}},
+			want:   []string{"dc1/web"},
+		},
+		{name: "no match", filter: &FleetFilter{States: []string{StateDeleting}}, want: []string{}},
+	}
+
+	for _, test := range tests {
+		clusters, err := fleet.GetClusters(test.filter)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		got := []string{}
+		for _, cluster := range clusters {
+			got = append(got, cluster.Instance+"/"+*cluster.Name)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: selected %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFleetErrors(t *testing.T) {
+
+	unavailable := errors.New("unavailable")
+
+	fleet := NewFleet().
+		Add("dc1", &fleetInstance{clusters: []Cluster{fleetCluster("web", StateReady, "1.10.1", "vsphere-1")}}).
+		Add("dc2", &fleetInstance{err: unavailable}).
+		Add("dc3", nil)
+
+	clusters, err := fleet.GetClusters(nil)
+
+	if len(clusters) != 1 || clusters[0].Instance != "dc1" {
+		t.Errorf("Returned %v, want the cluster of dc1", clusters)
+	}
+
+	fleetErr, ok := err.(*FleetError)
+	if !ok {
+		t.Fatalf("Returned %v, want a *FleetError", err)
+	}
+	if len(fleetErr.Errors) != 2 || fleetErr.Errors["dc2"] != unavailable || fleetErr.Errors["dc3"] == nil {
+		t.Errorf("Errors are %v, want dc2 unavailable and dc3 without a client", fleetErr.Errors)
+	}
+	if want := "2 of the fleet instances failed: dc2: unavailable; dc3: Instance dc3 has no client"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFleetDuplicateNames(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		fleet   *Fleet
+		wantErr bool
+	}{
+		{name: "unique", fleet: NewFleet().Add("dc1", &fleetInstance{}).Add("dc2", &fleetInstance{})},
+		{name: "added twice", fleet: NewFleet().Add("dc1", &fleetInstance{}).Add("dc1", &fleetInstance{}), wantErr: true},
+		{
+			name:    "failing instances with the same name",
+			fleet:   NewFleet().Add("dc1", &fleetInstance{err: errors.New("unavailable")}).Add("dc1", nil),
+			wantErr: true,
+		},
+		{
+			name:    "set directly",
+			fleet:   &Fleet{Instances: []FleetInstance{{Name: "dc1"}, {Name: "dc2"}, {Name: "dc1"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.fleet.GetClusters(nil)
+
+		// A duplicate name is rejected rather than reported as a *FleetError of the instances
+		_, partial := err.(*FleetError)
+
+		switch {
+		case test.wantErr && (err == nil || partial):
+			t.Errorf("%s: returned %v, want the duplicate name rejected", test.name, err)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
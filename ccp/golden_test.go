@@ -0,0 +1,523 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+// Golden files hold the requests made by a method and the responses CCP returned, one directory per CCP
+// version under testdata/golden. By default the responses are replayed from a local server, which checks
+// that each request matches the recorded one, so the tests run offline. To add a CCP version, write a
+// vars.json naming existing objects in testdata/golden/<version> and record against a real CCP:
+//
+//	CCP_URL=https://ccp.example.com CCP_PASSWORD=... go test ./ccp -run TestGolden -record -ccp-version 1.6.0
+//
+// The source field of each file says where it came from. Directories named synthetic-<version> were
+// written by hand to match the API of that version and should be replaced by a recording
+//
+// Recording creates, changes and deletes clusters and users, so only use a CCP set aside for testing.
+// Sensitive fields are redacted from JSON bodies but text responses, such as the kubeconfig returned by
+// GetClusterEnv, are stored as returned and must be checked before committing them
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	record        = flag.Bool("record", false, "record golden files from the CCP at $CCP_URL instead of replaying them")
+	recordVersion = flag.String("ccp-version", "", "CCP version directory under testdata/golden to record into")
+)
+
+const goldenDir = "testdata/golden"
+
+type golden struct {
+	// Source is "recorded" for files recorded from a CCP, and starts with "synthetic" for files written
+	// by hand
+	Source    string     `json:"source,omitempty"`
+	Exchanges []exchange `json:"exchanges"`
+}
+
+type exchange struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  url.Values      `json:"query,omitempty"`
+	Form   url.Values      `json:"form,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// recordedResponse holds a JSON response in Body and any other response in Text
+type recordedResponse struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Text        string          `json:"text,omitempty"`
+}
+
+// goldenVars name the objects used by the tests, which must exist on the CCP being recorded
+type goldenVars struct {
+	LoginUsername  string `json:"login_username"`
+	Username       string `json:"username"`
+	ClusterName    string `json:"cluster_name"`
+	ClusterUUID    string `json:"cluster_uuid"`
+	ProviderUUID   string `json:"provider_client_config_uuid"`
+	Datacenter     string `json:"datacenter"`
+	VsphereCluster string `json:"vsphere_cluster"`
+}
+
+// captureRequest returns the recorded form of a request, with sensitive values redacted
+func captureRequest(req *http.Request, body []byte) recordedRequest {
+
+	r := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.EscapedPath(),
+	}
+
+	if req.URL.RawQuery != "" {
+		r.Query = redactValues(req.URL.Query())
+	}
+
+	if len(body) > 0 {
+		if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			form, _ := url.ParseQuery(string(body))
+			r.Form = redactValues(form)
+		} else {
+			r.Body = RedactJSON(body)
+		}
+	}
+
+	return r
+}
+
+func captureResponse(resp *http.Response, body []byte) recordedResponse {
+
+	r := recordedResponse{Status: resp.StatusCode}
+
+	contentType := resp.Header.Get("Content-Type")
+
+	if clean := RedactJSON(body); len(clean) > 0 {
+		r.Body = clean
+		if !strings.HasPrefix(contentType, "application/json") {
+			r.ContentType = contentType
+		}
+	} else {
+		r.Text = string(body)
+		r.ContentType = contentType
+	}
+
+	return r
+}
+
+func (r recordedResponse) write(w http.ResponseWriter) {
+
+	contentType := r.ContentType
+	if contentType == "" && r.Body != nil {
+		contentType = "application/json"
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.WriteHeader(r.Status)
+
+	if r.Body != nil {
+		w.Write(r.Body)
+	} else {
+		w.Write([]byte(r.Text))
+	}
+}
+
+func loadJSON(t *testing.T, path string, v interface{}) {
+
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
+
+// replayServer serves the recorded responses in order, reporting any request which differs from the
+// recording. The returned function reports any recorded request which was never made
+func replayServer(t *testing.T, g golden) (*httptest.Server, func()) {
+
+	var (
+		mu   sync.Mutex
+		next int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		i := next
+		next++
+		mu.Unlock()
+
+		got := captureRequest(r, body)
+
+		if i >= len(g.Exchanges) {
+			t.Errorf("unexpected request %s %s", got.Method, got.Path)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		want := g.Exchanges[i]
+
+		if diffs := diffRequest(want.Request, got); len(diffs) > 0 {
+			t.Errorf("request %d does not match the recording:\n\t%s", i+1, strings.Join(diffs, "\n\t"))
+			http.Error(w, "request does not match the recording", http.StatusBadRequest)
+			return
+		}
+
+		want.Response.write(w)
+	}))
+
+	done := func() {
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if next < len(g.Exchanges) {
+			t.Errorf("%d of %d recorded requests were not made", len(g.Exchanges)-next, len(g.Exchanges))
+		}
+	}
+
+	return srv, done
+}
+
+func diffRequest(want, got recordedRequest) []string {
+
+	var diffs []string
+
+	if want.Method != got.Method || want.Path != got.Path {
+		diffs = append(diffs, fmt.Sprintf("sent %s %s, recorded %s %s", got.Method, got.Path, want.Method, want.Path))
+	}
+	if len(want.Query) > 0 || len(got.Query) > 0 {
+		if !reflect.DeepEqual(want.Query, got.Query) {
+			diffs = append(diffs, fmt.Sprintf("sent query %v, recorded %v", got.Query, want.Query))
+		}
+	}
+	if len(want.Form) > 0 || len(got.Form) > 0 {
+		if !reflect.DeepEqual(want.Form, got.Form) {
+			diffs = append(diffs, fmt.Sprintf("sent form %v, recorded %v", got.Form, want.Form))
+		}
+	}
+	if len(want.Body) > 0 || len(got.Body) > 0 {
+		diffs = append(diffs, diffJSON("body", decodeJSON(want.Body), decodeJSON(got.Body))...)
+	}
+
+	return diffs
+}
+
+// decodeJSON decodes a document for comparison, dropping null values since they are omitted when the
+// document is decoded into the pointer fields of the models
+func decodeJSON(data []byte) interface{} {
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+
+	if err := decoder.Decode(&v); err != nil {
+		return string(data)
+	}
+
+	return dropNulls(v)
+}
+
+func dropNulls(v interface{}) interface{} {
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if child == nil {
+				delete(v, k)
+			} else {
+				v[k] = dropNulls(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = dropNulls(child)
+		}
+	}
+
+	return v
+}
+
+// diffJSON returns a description of every difference between two decoded documents
+func diffJSON(path string, want, got interface{}) []string {
+
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range w {
+			keys[k] = true
+		}
+		for k := range g {
+			keys[k] = true
+		}
+		sorted := []string{}
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var diffs []string
+		for _, k := range sorted {
+			wv, inWant := w[k]
+			gv, inGot := g[k]
+			switch {
+			case !inGot:
+				diffs = append(diffs, fmt.Sprintf("%s.%s is missing, want %v", path, k, wv))
+			case !inWant:
+				diffs = append(diffs, fmt.Sprintf("%s.%s = %v is unexpected", path, k, gv))
+			default:
+				diffs = append(diffs, diffJSON(path+"."+k, wv, gv)...)
+			}
+		}
+		return diffs
+
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			break
+		}
+		var diffs []string
+		for i := range w {
+			diffs = append(diffs, diffJSON(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		return []string{fmt.Sprintf("%s = %v, want %v", path, got, want)}
+	}
+
+	return nil
+}
+
+// checkRoundTrip checks that v encodes to the same document as the recorded response, so no field of
+// the response is lost or changed when decoded into the models
+func checkRoundTrip(t *testing.T, resp recordedResponse, v interface{}) {
+
+	t.Helper()
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs := diffJSON("response", decodeJSON(resp.Body), decodeJSON(encoded)); len(diffs) > 0 {
+		t.Errorf("response does not survive decoding:\n\t%s", strings.Join(diffs, "\n\t"))
+	}
+}
+
+// recorder is middleware which records every exchange made through a client
+type recorder struct {
+	mu        sync.Mutex
+	exchanges []exchange
+}
+
+func (r *recorder) middleware(next http.RoundTripper) http.RoundTripper {
+
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+		body, err := RequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+		r.mu.Lock()
+		r.exchanges = append(r.exchanges, exchange{
+			Request:  captureRequest(req, body),
+			Response: captureResponse(resp, respBody),
+		})
+		r.mu.Unlock()
+
+		return resp, nil
+	})
+}
+
+func (r *recorder) save(path string) error {
+
+	data, err := json.MarshalIndent(golden{Source: "recorded", Exchanges: r.exchanges}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func TestGolden(t *testing.T) {
+
+	if *record {
+		recordGolden(t)
+		return
+	}
+
+	versions, err := ioutil.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range versions {
+		if !version.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(goldenDir, version.Name())
+
+		var vars goldenVars
+		loadJSON(t, filepath.Join(dir, "vars.json"), &vars)
+
+		t.Run(version.Name(), func(t *testing.T) {
+			for _, c := range goldenCases {
+				c := c
+				t.Run(c.name, func(t *testing.T) {
+					replayCase(t, filepath.Join(dir, c.name+".json"), vars, c)
+				})
+			}
+		})
+	}
+}
+
+func replayCase(t *testing.T, path string, vars goldenVars, c goldenCase) {
+
+	var g golden
+	loadJSON(t, path, &g)
+
+	if len(g.Exchanges) == 0 {
+		t.Fatalf("%s has no recorded requests", path)
+	}
+
+	srv, done := replayServer(t, g)
+	defer srv.Close()
+
+	client := NewClient(vars.LoginUsername, "password", srv.URL)
+
+	result, err := c.call(client, vars)
+
+	done()
+
+	last := g.Exchanges[len(g.Exchanges)-1].Response
+
+	if last.Status >= 300 {
+		if err == nil {
+			t.Fatalf("expected the recorded %d response to return an error", last.Status)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case c.model != nil:
+		if err := json.Unmarshal(last.Body, c.model); err != nil {
+			t.Fatal(err)
+		}
+		checkRoundTrip(t, last, c.model)
+
+	case last.Body != nil:
+		checkRoundTrip(t, last, result)
+
+	case result != nil:
+		text, ok := result.(*string)
+		if !ok || *text != last.Text {
+			t.Errorf("returned %v, want the recorded text %q", result, last.Text)
+		}
+	}
+}
+
+func recordGolden(t *testing.T) {
+
+	baseURL := os.Getenv("CCP_URL")
+	if baseURL == "" || *recordVersion == "" {
+		t.Fatal("recording requires CCP_URL and -ccp-version")
+	}
+
+	dir := filepath.Join(goldenDir, *recordVersion)
+
+	var vars goldenVars
+	loadJSON(t, filepath.Join(dir, "vars.json"), &vars)
+
+	for _, c := range goldenCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+
+			client := NewClient(vars.LoginUsername, os.Getenv(EnvPassword), baseURL)
+
+			if c.name != "Login" {
+				if err := client.Login(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec := &recorder{}
+			client.Use(rec.middleware)
+
+			if _, err := c.call(client, vars); err != nil {
+				t.Log(err)
+			}
+
+			if err := rec.save(filepath.Join(dir, c.name+".json")); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
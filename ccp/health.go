@@ -0,0 +1,177 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"fmt"
+)
+
+// HealthyStatus is the TotalSystemHealth of a healthy cluster or control plane
+const HealthyStatus = "Healthy"
+
+// Kinds of HealthComponent
+const (
+	ComponentSystem = "system"
+	ComponentNodes  = "nodes"
+	ComponentNode   = "node"
+	ComponentPod    = "pod"
+)
+
+// ClusterHealth is the health of a tenant cluster, as returned by GetClusterHealth
+type ClusterHealth struct {
+	TotalSystemHealth *string          `json:"TotalSystemHealth,omitempty"`
+	CurrentNodes      *int64           `json:"CurrentNodes,omitempty"`
+	ExpectedNodes     *int64           `json:"ExpectedNodes,omitempty"`
+	NodesStatus       *[]NodeStatus    `json:"NodesStatus,omitempty"`
+	PodStatusList     *[]PodStatusList `json:"PodStatusList,omitempty"`
+}
+
+// HealthComponent is a part of a cluster or control plane which is not healthy
+type HealthComponent struct {
+	// Kind is ComponentSystem for the overall health, ComponentNodes for missing nodes, or ComponentNode
+	// or ComponentPod for the condition of a node or pod
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"`
+	Condition string `json:"condition,omitempty"`
+	Status    string `json:"status"`
+	// Reason describes the problem, e.g. "Node demo-worker1 is not Ready"
+	Reason string `json:"reason"`
+}
+
+// Healthy reports whether the cluster reports itself healthy and has no unhealthy component
+func (h *ClusterHealth) Healthy() bool {
+	return len(h.Unhealthy()) == 0
+}
+
+// Unhealthy returns the failing components of the cluster: the overall health when it is not Healthy,
+// missing nodes, and nodes and pods whose conditions are failing
+func (h *ClusterHealth) Unhealthy() []HealthComponent {
+	return unhealthyComponents(h.TotalSystemHealth, h.CurrentNodes, h.ExpectedNodes, h.NodesStatus, h.PodStatusList)
+}
+
+// Healthy reports whether the control plane reports itself healthy and has no unhealthy component
+func (h *Health) Healthy() bool {
+	return len(h.Unhealthy()) == 0
+}
+
+// Unhealthy returns the failing components of the control plane, like ClusterHealth.Unhealthy
+func (h *Health) Unhealthy() []HealthComponent {
+	return unhealthyComponents(h.TotalSystemHealth, h.CurrentNodes, h.ExpectedNodes, h.NodesStatus, h.PodStatusList)
+}
+
+// unhealthyComponents lists the failing components of a cluster or control plane health document
+func unhealthyComponents(total *string, current, expected *int64, nodes *[]NodeStatus, pods *[]PodStatusList) []HealthComponent {
+
+	var failing []HealthComponent
+
+	if total != nil && *total != HealthyStatus {
+		failing = append(failing, HealthComponent{
+			Kind:   ComponentSystem,
+			Status: *total,
+			Reason: fmt.Sprintf("Health is %s", *total),
+		})
+	}
+
+	if current != nil && expected != nil && *current < *expected {
+		failing = append(failing, HealthComponent{
+			Kind:   ComponentNodes,
+			Status: fmt.Sprintf("%d/%d", *current, *expected),
+			Reason: fmt.Sprintf("Only %d of %d expected nodes are up", *current, *expected),
+		})
+	}
+
+	if nodes != nil {
+		for _, node := range *nodes {
+			condition, status := derefString(node.NodeCondition), derefString(node.NodeStatus)
+			if !conditionHealthy(condition, status) {
+				failing = append(failing, HealthComponent{
+					Kind:      ComponentNode,
+					Name:      derefString(node.NodeName),
+					Condition: condition,
+					Status:    status,
+					Reason:    conditionReason("Node", derefString(node.NodeName), condition, status),
+				})
+			}
+		}
+	}
+
+	if pods != nil {
+		for _, pod := range *pods {
+			condition, status := derefString(pod.PodCondition), derefString(pod.PodStatus)
+			if !podHealthy(condition, status) {
+				failing = append(failing, HealthComponent{
+					Kind:      ComponentPod,
+					Name:      derefString(pod.PodName),
+					Condition: condition,
+					Status:    status,
+					Reason:    conditionReason("Pod", derefString(pod.PodName), condition, status),
+				})
+			}
+		}
+	}
+
+	return failing
+}
+
+// problemConditions are the Kubernetes node conditions which are healthy when False
+var problemConditions = map[string]bool{
+	"MemoryPressure":     true,
+	"DiskPressure":       true,
+	"PIDPressure":        true,
+	"OutOfDisk":          true,
+	"NetworkUnavailable": true,
+}
+
+// conditionHealthy reports whether a Kubernetes condition has its healthy status. Conditions such as
+// Ready are healthy when True, and problem conditions such as DiskPressure when False
+func conditionHealthy(condition, status string) bool {
+
+	if problemConditions[condition] {
+		return status == "False"
+	}
+
+	return status == "True"
+}
+
+// healthyPodStatuses are the statuses of a healthy pod. CCP may report the pod phase or readiness
+// rather than the status of the condition
+var healthyPodStatuses = map[string]bool{
+	"True":    true,
+	"Running": true,
+	"Ready":   true,
+}
+
+// podHealthy is conditionHealthy for a pod, also accepting a pod which is Running or Ready
+func podHealthy(condition, status string) bool {
+
+	if problemConditions[condition] {
+		return status == "False"
+	}
+
+	return healthyPodStatuses[status]
+}
+
+func conditionReason(kind, name, condition, status string) string {
+
+	if problemConditions[condition] {
+		return fmt.Sprintf("%s %s has %s", kind, name, condition)
+	}
+	if status == "Unknown" || status == "" {
+		return fmt.Sprintf("%s %s is %s Unknown", kind, name, condition)
+	}
+
+	return fmt.Sprintf("%s %s is not %s", kind, name, condition)
+}
@@ -0,0 +1,62 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPodHealth(t *testing.T) {
+
+	tests := []struct {
+		condition string
+		status    string
+		// reasons are those of the failing components, none for a healthy pod
+		reasons []string
+	}{
+		{condition: "Ready", status: "True"},
+		{condition: "Ready", status: "Running"},
+		{condition: "Ready", status: "Ready"},
+		{condition: "Ready", status: "False", reasons: []string{"Pod cx-api-0 is not Ready"}},
+		{condition: "Ready", status: "Pending", reasons: []string{"Pod cx-api-0 is not Ready"}},
+		{condition: "Ready", status: "CrashLoopBackOff", reasons: []string{"Pod cx-api-0 is not Ready"}},
+		{condition: "Ready", status: "Unknown", reasons: []string{"Pod cx-api-0 is Ready Unknown"}},
+		{condition: "Ready", status: "", reasons: []string{"Pod cx-api-0 is Ready Unknown"}},
+		{condition: "DiskPressure", status: "False"},
+		{condition: "DiskPressure", status: "Running", reasons: []string{"Pod cx-api-0 has DiskPressure"}},
+	}
+
+	for _, test := range tests {
+		health := &ClusterHealth{PodStatusList: &[]PodStatusList{{
+			PodName:      String("cx-api-0"),
+			PodCondition: String(test.condition),
+			PodStatus:    String(test.status),
+		}}}
+
+		var reasons []string
+		for _, component := range health.Unhealthy() {
+			reasons = append(reasons, component.Reason)
+		}
+
+		if !reflect.DeepEqual(reasons, test.reasons) {
+			t.Errorf("%s %q: reasons are %q, want %q", test.condition, test.status, reasons, test.reasons)
+		}
+		if health.Healthy() != (len(test.reasons) == 0) {
+			t.Errorf("%s %q: Healthy() = %v", test.condition, test.status, health.Healthy())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RequestInfo describes a CCP API call
+type RequestInfo struct {
+	Method string
+	// Endpoint is the path template including the version prefix, e.g. /2/clusters/{uuid}. Unlike the
+	// URL it does not vary per resource, so it is suitable as a span name or metric label
+	Endpoint string
+	// URL is the full request URL with any sensitive query parameters redacted
+	URL string
+}
+
+// RequestResult describes the outcome of a CCP API call
+type RequestResult struct {
+	// StatusCode is zero when no response was received
+	StatusCode int
+	// Err is set for transport failures and for responses with an error status
+	Err      error
+	Duration time.Duration
+	// Retries is the number of times the request was sent after the first attempt, e.g. by a retrying
+	// middleware or when following a redirect
+	Retries int
+}
+
+// Tracer is called at the start of every API call. The returned context is used for the request so
+// that middleware can propagate the span, and the returned function is called once the call completes
+type Tracer interface {
+	StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult))
+}
+
+// Metrics is called once every API call completes
+type Metrics interface {
+	ObserveRequest(info RequestInfo, result RequestResult)
+}
+
+type attemptsKey struct{}
+
+// attemptTransport counts the times a request is sent over the network
+type attemptTransport struct {
+	next http.RoundTripper
+}
+
+func (t attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if attempts, ok := req.Context().Value(attemptsKey{}).(*int32); ok {
+		atomic.AddInt32(attempts, 1)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// instrument notifies the Tracer of the start of the request. The returned function must be called with
+// the outcome of the request
+func (s *Client) instrument(req *http.Request) (*http.Request, func(status int, err error)) {
+
+	if s.Tracer == nil && s.Metrics == nil {
+		return req, func(int, error) {}
+	}
+
+	info := RequestInfo{
+		Method:   req.Method,
+		Endpoint: "/" + s.apiVersion() + EndpointTemplate(req),
+		URL:      redactURL(req.URL),
+	}
+
+	attempts := new(int32)
+	ctx := context.WithValue(req.Context(), attemptsKey{}, attempts)
+
+	var end func(RequestResult)
+	if s.Tracer != nil {
+		ctx, end = s.Tracer.StartRequest(ctx, info)
+	}
+
+	start := time.Now()
+
+	return req.WithContext(ctx), func(status int, err error) {
+
+		result := RequestResult{
+			StatusCode: status,
+			Err:        err,
+			Duration:   time.Since(start),
+		}
+		if n := atomic.LoadInt32(attempts); n > 1 {
+			result.Retries = int(n - 1)
+		}
+
+		if end != nil {
+			end(result)
+		}
+		if s.Metrics != nil {
+			s.Metrics.ObserveRequest(info, result)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrLabelConflict is returned when the labels of a cluster keep changing while they are being updated
+var ErrLabelConflict = errors.New("Labels of the cluster were changed by someone else while being updated, try again")
+
+// SetClusterLabels replaces every label of the named cluster. See AddClusterLabel for how concurrent
+// changes are detected
+func (s *Client) SetClusterLabels(ctx context.Context, clusterName string, labels map[string]string) (*Cluster, error) {
+
+	for key := range labels {
+		if err := validLabelToken(key, "key"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.updateClusterLabels(ctx, clusterName, func(current []Label) []Label {
+
+		keys := make([]string, 0, len(labels))
+		for key := range labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		updated := []Label{}
+		for _, key := range keys {
+			updated = append(updated, Label{Key: String(key), Value: String(labels[key])})
+		}
+
+		return updated
+	})
+}
+
+// AddClusterLabel adds a label to the named cluster, or changes the value of an existing label. CCP
+// has no way of updating a single label, so the labels are read, changed and written back with
+// PatchCluster. The labels are read again after the patch and, when they are not those written, the
+// update is retried from the start. ErrLabelConflict is returned when they keep changing. This is best
+// effort: CCP has no conditional update, so a change made by someone else between the read and the
+// patch is overwritten without being noticed
+func (s *Client) AddClusterLabel(ctx context.Context, clusterName, key, value string) (*Cluster, error) {
+
+	if err := validLabelToken(key, "key"); err != nil {
+		return nil, err
+	}
+
+	return s.updateClusterLabels(ctx, clusterName, func(current []Label) []Label {
+
+		for i, label := range current {
+			if derefString(label.Key) == key {
+				current[i].Value = String(value)
+				return current
+			}
+		}
+
+		return append(current, Label{Key: String(key), Value: String(value)})
+	})
+}
+
+// RemoveClusterLabel removes a label from the named cluster. Removing a label the cluster does not
+// have is not an error. See AddClusterLabel for how concurrent changes are detected
+func (s *Client) RemoveClusterLabel(ctx context.Context, clusterName, key string) (*Cluster, error) {
+
+	return s.updateClusterLabels(ctx, clusterName, func(current []Label) []Label {
+
+		updated := []Label{}
+		for _, label := range current {
+			if derefString(label.Key) != key {
+				updated = append(updated, label)
+			}
+		}
+
+		return updated
+	})
+}
+
+// updateClusterLabels patches the labels of a cluster with those returned by change, which is given a
+// copy of the current labels, and checks they were kept
+func (s *Client) updateClusterLabels(ctx context.Context, clusterName string, change func(current []Label) []Label) (*Cluster, error) {
+
+	var updated *Cluster
+
+	err := retryUpdate(ErrLabelConflict, func() (bool, error) {
+
+		cluster, err := s.GetClusterContext(ctx, clusterName)
+		if err != nil {
+			return false, err
+		}
+		if cluster == nil || nonzero(cluster.UUID) {
+			return false, fmt.Errorf("Cluster %s not found", clusterName)
+		}
+
+		labels := change(copyLabels(cluster.Labels))
+		want := labelMap(labels)
+
+		if reflect.DeepEqual(want, ClusterLabels(cluster)) {
+			updated = cluster
+			return true, nil
+		}
+
+		patched, err := s.PatchClusterContext(ctx, &Cluster{
+			UUID:   cluster.UUID,
+			Labels: &labels,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		check, err := s.GetClusterContext(ctx, clusterName)
+		if err != nil {
+			return false, err
+		}
+		if check == nil || derefString(check.UUID) != *cluster.UUID || !reflect.DeepEqual(ClusterLabels(check), want) {
+			return false, nil
+		}
+
+		updated = patched
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func copyLabels(labels *[]Label) []Label {
+
+	copied := []Label{}
+
+	if labels == nil {
+		return copied
+	}
+
+	for _, label := range *labels {
+		copied = append(copied, Label{Key: label.Key, Value: label.Value})
+	}
+
+	return copied
+}
+
+func labelMap(labels []Label) map[string]string {
+	return ClusterLabels(&Cluster{Labels: &labels})
+}
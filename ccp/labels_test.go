@@ -0,0 +1,222 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// clusterServer serves GET and PATCH of the cluster demo, with UUID 1234, and its auth list
+type clusterServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	cluster Cluster
+	patches int
+	// overwrite is applied after each of the first overwrites patches, as an update made by someone
+	// else would be
+	overwrite  func(cluster *Cluster)
+	overwrites int
+}
+
+func newClusterServer(cluster Cluster) *clusterServer {
+
+	s := &clusterServer{cluster: cluster}
+	s.cluster.UUID = String("1234")
+	s.cluster.Name = String("demo")
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/2/clusters/demo":
+			json.NewEncoder(w).Encode(s.cluster)
+		case r.Method == "GET" && r.URL.Path == "/2/clusters/1234/authz":
+			json.NewEncoder(w).Encode(ClusterAuthz{AuthList: s.cluster.AuthList})
+		case r.Method == "PATCH" && r.URL.Path == "/2/clusters/1234":
+			var patch Cluster
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if patch.Labels != nil {
+				s.cluster.Labels = patch.Labels
+			}
+			if patch.AuthList != nil {
+				s.cluster.AuthList = patch.AuthList
+			}
+			s.patches++
+			json.NewEncoder(w).Encode(s.cluster)
+			if s.overwrites > 0 {
+				s.overwrite(&s.cluster)
+				s.overwrites--
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return s
+}
+
+func TestClusterLabelUpdates(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		update  func(c *Client) (*Cluster, error)
+		want    *[]Label
+		patches int
+		wantErr bool
+	}{
+		{
+			name: "add",
+			update: func(c *Client) (*Cluster, error) {
+				return c.AddClusterLabel(context.Background(), "demo", "gpu", "true")
+			},
+			want:    labelList("env", "dev", "team", "web", "gpu", "true"),
+			patches: 1,
+		},
+		{
+			name: "change",
+			update: func(c *Client) (*Cluster, error) {
+				return c.AddClusterLabel(context.Background(), "demo", "env", "prod")
+			},
+			want:    labelList("env", "prod", "team", "web"),
+			patches: 1,
+		},
+		{
+			name: "add unchanged",
+			update: func(c *Client) (*Cluster, error) {
+				return c.AddClusterLabel(context.Background(), "demo", "env", "dev")
+			},
+			want: labelList("env", "dev", "team", "web"),
+		},
+		{
+			name:    "remove",
+			update:  func(c *Client) (*Cluster, error) { return c.RemoveClusterLabel(context.Background(), "demo", "env") },
+			want:    labelList("team", "web"),
+			patches: 1,
+		},
+		{
+			name:   "remove missing",
+			update: func(c *Client) (*Cluster, error) { return c.RemoveClusterLabel(context.Background(), "demo", "gpu") },
+			want:   labelList("env", "dev", "team", "web"),
+		},
+		{
+			name: "set",
+			update: func(c *Client) (*Cluster, error) {
+				return c.SetClusterLabels(context.Background(), "demo", map[string]string{"zone": "a", "env": "prod"})
+			},
+			want:    labelList("env", "prod", "zone", "a"),
+			patches: 1,
+		},
+		{
+			name:    "set none",
+			update:  func(c *Client) (*Cluster, error) { return c.SetClusterLabels(context.Background(), "demo", nil) },
+			want:    labelList(),
+			patches: 1,
+		},
+		{
+			name: "invalid key",
+			update: func(c *Client) (*Cluster, error) {
+				return c.AddClusterLabel(context.Background(), "demo", "bad key", "x")
+			},
+			want:    labelList("env", "dev", "team", "web"),
+			wantErr: true,
+		},
+		{
+			name: "missing cluster",
+			update: func(c *Client) (*Cluster, error) {
+				return c.AddClusterLabel(context.Background(), "other", "gpu", "true")
+			},
+			want:    labelList("env", "dev", "team", "web"),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		srv := newClusterServer(Cluster{Labels: labelList("env", "dev", "team", "web")})
+
+		cluster, err := test.update(NewClient("admin", "secret", srv.URL))
+
+		srv.Close()
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: succeeded, want an error", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case !test.wantErr && !reflect.DeepEqual(cluster.Labels, test.want):
+			t.Errorf("%s: returned labels %v, want %v", test.name, ClusterLabels(cluster), labelMap(*test.want))
+		}
+
+		if !reflect.DeepEqual(srv.cluster.Labels, test.want) {
+			got, _ := json.Marshal(srv.cluster.Labels)
+			want, _ := json.Marshal(test.want)
+			t.Errorf("%s: labels are %s, want %s", test.name, got, want)
+		}
+		if srv.patches != test.patches {
+			t.Errorf("%s: sent %d patches, want %d", test.name, srv.patches, test.patches)
+		}
+	}
+}
+
+func TestClusterLabelConflict(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		overwrites int
+		want       *[]Label
+		patches    int
+		err        error
+	}{
+		{name: "kept", overwrites: 0, want: labelList("env", "dev", "gpu", "true"), patches: 1},
+		{name: "overwritten once", overwrites: 1, want: labelList("env", "prod", "gpu", "true"), patches: 2},
+		{name: "overwritten twice", overwrites: 2, want: labelList("env", "prod", "gpu", "true"), patches: 3},
+		{name: "always overwritten", overwrites: updateAttempts, want: labelList("env", "prod"), patches: updateAttempts, err: ErrLabelConflict},
+	}
+
+	for _, test := range tests {
+		srv := newClusterServer(Cluster{Labels: labelList("env", "dev")})
+		srv.overwrites = test.overwrites
+		srv.overwrite = func(cluster *Cluster) {
+			cluster.Labels = labelList("env", "prod")
+		}
+
+		_, err := NewClient("admin", "secret", srv.URL).AddClusterLabel(context.Background(), "demo", "gpu", "true")
+
+		srv.Close()
+
+		if err != test.err {
+			t.Errorf("%s: AddClusterLabel returned %v, want %v", test.name, err, test.err)
+		}
+		if !reflect.DeepEqual(srv.cluster.Labels, test.want) {
+			t.Errorf("%s: labels are %v, want %v", test.name, labelMap(*srv.cluster.Labels), labelMap(*test.want))
+		}
+		if srv.patches != test.patches {
+			t.Errorf("%s: sent %d patches, want %d", test.name, srv.patches, test.patches)
+		}
+	}
+}
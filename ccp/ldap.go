@@ -17,8 +17,6 @@ package ccp
 
 import (
 	"encoding/json"
-	"fmt"
-	"net/http"
 )
 
 type LDAPSetup struct {
@@ -26,16 +24,14 @@ type LDAPSetup struct {
 	Port                   *int64  `json:"Port,omitempty" `
 	BaseDN                 *string `json:"BaseDN,omitempty"`
 	ServiceAccountDN       *string `json:"ServiceAccountDN,omitempty"`
-	ServiceAccountPassword *string `json:"ServiceAccountPassword,omitempty"`
+	ServiceAccountPassword *string `json:"ServiceAccountPassword,omitempty" sensitive:"true"`
 	StartTLS               *bool   `json:"StartTLS,omitempty"`
 	InsecureSkipVerify     *bool   `json:"InsecureSkipVerify,omitempty" `
 }
 
 func (s *Client) GetLDAPSetup() (*LDAPSetup, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/ldap/setup")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/ldap/setup"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,493 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ListOptions select which objects a list call returns. CCP has no server side filtering, so every
+// object is fetched and the options are applied by the client as the response is decoded. Options which
+// do not apply to the objects listed, such as State for users, are an error
+type ListOptions struct {
+	// LabelSelector selects clusters by their labels, e.g. "env=prod,team in (a,b)". See LabelSelector
+	LabelSelector string
+	// State selects clusters in the state, e.g. StateReady
+	State string
+	// NamePrefix selects objects whose name, or username for users, starts with the prefix
+	NamePrefix string
+	// ProviderClientConfigUUID selects clusters deployed with the provider client config
+	ProviderClientConfigUUID string
+
+	// SortBy is the JSON name of the field to sort on, e.g. "name" or "kubernetes_version", prefixed
+	// with - to sort in descending order. Sorting needs every object, so a sorted list is never streamed
+	SortBy string
+
+	// Offset skips the first selected objects and Limit, when above zero, is the most objects returned
+	Offset int
+	Limit  int
+}
+
+// listFilter is ListOptions checked and prepared for the objects of a list call
+type listFilter struct {
+	ListOptions
+	selector   *LabelSelector
+	sortField  []int
+	descending bool
+}
+
+func newListFilter(opts ListOptions, model interface{}) (*listFilter, error) {
+
+	f := &listFilter{ListOptions: opts}
+
+	if _, ok := model.(Cluster); !ok {
+		for name, set := range map[string]bool{
+			"LabelSelector":            opts.LabelSelector != "",
+			"State":                    opts.State != "",
+			"ProviderClientConfigUUID": opts.ProviderClientConfigUUID != "",
+		} {
+			if set {
+				return nil, fmt.Errorf("ListOptions.%s only applies to clusters", name)
+			}
+		}
+	}
+
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return nil, errors.New("ListOptions.Offset and ListOptions.Limit cannot be negative")
+	}
+
+	selector, err := ParseLabelSelector(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	f.selector = selector
+
+	if opts.SortBy != "" {
+		name := strings.TrimPrefix(opts.SortBy, "-")
+		f.descending = name != opts.SortBy
+
+		t := reflect.TypeOf(model)
+		for i := 0; i < t.NumField(); i++ {
+			if jsonName(t.Field(i)) == name {
+				f.sortField = t.Field(i).Index
+			}
+		}
+		if f.sortField == nil {
+			return nil, fmt.Errorf("Cannot sort %s by %q, it has no such field", t.Name(), name)
+		}
+	}
+
+	return f, nil
+}
+
+// match reports whether the filter selects an object
+func (f *listFilter) match(v interface{}) bool {
+
+	var name *string
+
+	switch o := v.(type) {
+	case *Cluster:
+		if f.State != "" && derefString(o.State) != f.State {
+			return false
+		}
+		if f.ProviderClientConfigUUID != "" && derefString(o.ProviderClientConfigUUID) != f.ProviderClientConfigUUID {
+			return false
+		}
+		if !f.selector.Matches(ClusterLabels(o)) {
+			return false
+		}
+		name = o.Name
+	case *User:
+		name = o.Username
+	case *ProviderClientConfig:
+		name = o.Name
+	case *ACIProfile:
+		name = o.Name
+	}
+
+	return strings.HasPrefix(derefString(name), f.NamePrefix)
+}
+
+// complete reports whether enough objects were selected to stop decoding the rest of the response
+func (f *listFilter) complete(selected int) bool {
+	return f.Limit > 0 && f.sortField == nil && selected >= f.Offset+f.Limit
+}
+
+// apply sorts the selected objects, held in the slice pointed to by list, and applies Offset and Limit
+func (f *listFilter) apply(list interface{}) {
+
+	v := reflect.ValueOf(list).Elem()
+
+	if f.sortField != nil {
+		sort.SliceStable(v.Interface(), func(i, j int) bool {
+			a := v.Index(i).FieldByIndex(f.sortField)
+			b := v.Index(j).FieldByIndex(f.sortField)
+			if f.descending {
+				return lessField(b, a)
+			}
+			return lessField(a, b)
+		})
+	}
+
+	start, end := f.Offset, v.Len()
+	if start > end {
+		start = end
+	}
+	if f.Limit > 0 && start+f.Limit < end {
+		end = start + f.Limit
+	}
+
+	v.Set(v.Slice(start, end))
+}
+
+// lessField orders two values of a pointer field, with unset values first
+func lessField(a, b reflect.Value) bool {
+
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && !b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Int, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+
+	return false
+}
+
+// jsonStream decodes the elements of a JSON array response one at a time
+type jsonStream struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	started bool
+	done    bool
+}
+
+// stream sends a request for a JSON array and returns a stream of its elements, which must be closed
+func (s *Client) stream(req *http.Request) (*jsonStream, error) {
+
+	body, err := s.doStream(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonStream{body: body, decoder: json.NewDecoder(body)}, nil
+}
+
+// next decodes the next element into v, returning false at the end of the array
+func (j *jsonStream) next(v interface{}) (bool, error) {
+
+	if j.done {
+		return false, nil
+	}
+
+	if !j.started {
+		j.started = true
+		token, err := j.decoder.Token()
+		if err == io.EOF || (err == nil && token == nil) {
+			// An empty body or null is an empty list
+			j.done = true
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if token != json.Delim('[') {
+			return false, fmt.Errorf("Expected a JSON array in the response, found %v", token)
+		}
+	}
+
+	if !j.decoder.More() {
+		j.done = true
+		_, err := j.decoder.Token()
+		return false, err
+	}
+
+	return true, j.decoder.Decode(v)
+}
+
+func (j *jsonStream) close() error {
+	return j.body.Close()
+}
+
+// list decodes the objects selected by f from a JSON array response into the slice pointed to by out
+func (s *Client) list(req *http.Request, f *listFilter, out interface{}) error {
+
+	stream, err := s.stream(req)
+	if err != nil {
+		return err
+	}
+	defer stream.close()
+
+	list := reflect.ValueOf(out).Elem()
+	list.Set(reflect.MakeSlice(list.Type(), 0, 0))
+
+	for !f.complete(list.Len()) {
+		item := reflect.New(list.Type().Elem())
+		ok, err := stream.next(item.Interface())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if f.match(item.Interface()) {
+			list.Set(reflect.Append(list, item.Elem()))
+		}
+	}
+
+	f.apply(out)
+
+	return nil
+}
+
+// ListClusters returns the clusters selected by opts
+func (s *Client) ListClusters(opts ListOptions) ([]Cluster, error) {
+	return s.ListClustersContext(context.Background(), opts)
+}
+
+// ListClustersContext is ListClusters with a context for cancellation
+func (s *Client) ListClustersContext(ctx context.Context, opts ListOptions) ([]Cluster, error) {
+
+	f, err := newListFilter(opts, Cluster{})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []Cluster
+
+	if err := s.list(req, f, &clusters); err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// ListUsers returns the users selected by opts
+func (s *Client) ListUsers(opts ListOptions) ([]User, error) {
+	return s.ListUsersContext(context.Background(), opts)
+}
+
+// ListUsersContext is ListUsers with a context for cancellation
+func (s *Client) ListUsersContext(ctx context.Context, opts ListOptions) ([]User, error) {
+
+	f, err := newListFilter(opts, User{})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/localusers"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+
+	if err := s.list(req, f, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ListProviderClientConfigs returns the provider client configs selected by opts
+func (s *Client) ListProviderClientConfigs(opts ListOptions) ([]ProviderClientConfig, error) {
+	return s.ListProviderClientConfigsContext(context.Background(), opts)
+}
+
+// ListProviderClientConfigsContext is ListProviderClientConfigs with a context for cancellation
+func (s *Client) ListProviderClientConfigsContext(ctx context.Context, opts ListOptions) ([]ProviderClientConfig, error) {
+
+	f, err := newListFilter(opts, ProviderClientConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/providerclientconfigs"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []ProviderClientConfig
+
+	if err := s.list(req, f, &configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+// ListACIProfiles returns the ACI profiles selected by opts
+func (s *Client) ListACIProfiles(opts ListOptions) ([]ACIProfile, error) {
+	return s.ListACIProfilesContext(context.Background(), opts)
+}
+
+// ListACIProfilesContext is ListACIProfiles with a context for cancellation
+func (s *Client) ListACIProfilesContext(ctx context.Context, opts ListOptions) ([]ACIProfile, error) {
+
+	f, err := newListFilter(opts, ACIProfile{})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/aci_profiles"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []ACIProfile
+
+	if err := s.list(req, f, &profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// ClusterIterator steps through the clusters of a list one at a time, decoding each from the response
+// as it is reached so the whole list is never held in memory. It must be closed
+//
+//	it, err := client.IterateClusters(ctx, ccp.ListOptions{State: ccp.StateReady})
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//
+//	for it.Next() {
+//		fmt.Println(*it.Cluster().Name)
+//	}
+//	return it.Err()
+type ClusterIterator struct {
+	filter   *listFilter
+	stream   *jsonStream
+	sorted   []Cluster
+	cluster  *Cluster
+	selected int
+	err      error
+}
+
+// IterateClusters returns an iterator over the clusters selected by opts. When opts.SortBy is set every
+// cluster must be read before the first is returned, so the selected clusters are held in memory
+func (s *Client) IterateClusters(ctx context.Context, opts ListOptions) (*ClusterIterator, error) {
+
+	f, err := newListFilter(opts, Cluster{})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/clusters"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.sortField != nil {
+		var sorted []Cluster
+		if err := s.list(req, f, &sorted); err != nil {
+			return nil, err
+		}
+		return &ClusterIterator{filter: f, sorted: sorted}, nil
+	}
+
+	stream, err := s.stream(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClusterIterator{filter: f, stream: stream}, nil
+}
+
+// Next advances to the next cluster, returning false at the end of the list or on an error
+func (it *ClusterIterator) Next() bool {
+
+	it.cluster = nil
+
+	if it.err != nil {
+		return false
+	}
+
+	if it.stream == nil {
+		if len(it.sorted) == 0 {
+			return false
+		}
+		it.cluster = &it.sorted[0]
+		it.sorted = it.sorted[1:]
+		return true
+	}
+
+	for !it.filter.complete(it.selected) {
+		var cluster Cluster
+		ok, err := it.stream.next(&cluster)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !ok {
+			return false
+		}
+		if !it.filter.match(&cluster) {
+			continue
+		}
+		it.selected++
+		if it.selected > it.filter.Offset {
+			it.cluster = &cluster
+			return true
+		}
+	}
+
+	return false
+}
+
+// Cluster returns the current cluster
+func (it *ClusterIterator) Cluster() *Cluster {
+	return it.cluster
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *ClusterIterator) Err() error {
+	return it.err
+}
+
+// Close releases the response. It can be called before the end of the list
+func (it *ClusterIterator) Close() error {
+
+	if it.stream == nil {
+		return nil
+	}
+
+	return it.stream.close()
+}
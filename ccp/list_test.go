@@ -0,0 +1,260 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// listServer answers every request with body
+func listServer(body string) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+// listClusters is the body of a cluster list, in the order CCP returns it
+func listClusters(t *testing.T) string {
+
+	body, err := json.Marshal([]Cluster{
+		fleetCluster("web", StateReady, "1.10.1", "vsphere-1", "env", "prod"),
+		fleetCluster("batch", StateError, "1.11.0", "vsphere-1", "env", "dev"),
+		fleetCluster("web-2", StateReady, "1.10.3", "vsphere-2", "env", "prod"),
+		fleetCluster("gpu", StateCreating, "1.11.0", "vsphere-2"),
+		fleetCluster("api", StateReady, "1.10.1", "vsphere-1", "env", "dev"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
+
+func clusterNames(clusters []Cluster) []string {
+
+	names := []string{}
+	for _, cluster := range clusters {
+		names = append(names, *cluster.Name)
+	}
+
+	return names
+}
+
+func TestListClusters(t *testing.T) {
+
+	srv := listServer(listClusters(t))
+	defer srv.Close()
+
+	client := NewClient("admin", "secret", srv.URL)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{name: "everything", want: []string{"web", "batch", "web-2", "gpu", "api"}},
+		{name: "state", opts: ListOptions{State: StateReady}, want: []string{"web", "web-2", "api"}},
+		{name: "name prefix", opts: ListOptions{NamePrefix: "web"}, want: []string{"web", "web-2"}},
+		{name: "provider", opts: ListOptions{ProviderClientConfigUUID: "vsphere-2"}, want: []string{"web-2", "gpu"}},
+		{name: "labels", opts: ListOptions{LabelSelector: "env=dev"}, want: []string{"batch", "api"}},
+		{name: "sorted", opts: ListOptions{SortBy: "name"}, want: []string{"api", "batch", "gpu", "web", "web-2"}},
+		{name: "sorted descending", opts: ListOptions{SortBy: "-name"}, want: []string{"web-2", "web", "gpu", "batch", "api"}},
+		{name: "sorted stable", opts: ListOptions{SortBy: "kubernetes_version"}, want: []string{"web", "api", "web-2", "batch", "gpu"}},
+
+		{name: "first page", opts: ListOptions{Limit: 2}, want: []string{"web", "batch"}},
+		{name: "middle page", opts: ListOptions{Offset: 2, Limit: 2}, want: []string{"web-2", "gpu"}},
+		{name: "last page short", opts: ListOptions{Offset: 4, Limit: 2}, want: []string{"api"}},
+		{name: "last page full", opts: ListOptions{Offset: 3, Limit: 2}, want: []string{"gpu", "api"}},
+		{name: "page after the end", opts: ListOptions{Offset: 5, Limit: 2}, want: []string{}},
+		{name: "offset past the end", opts: ListOptions{Offset: 9}, want: []string{}},
+		{name: "limit above the count", opts: ListOptions{Limit: 9}, want: []string{"web", "batch", "web-2", "gpu", "api"}},
+		{name: "offset only", opts: ListOptions{Offset: 3}, want: []string{"gpu", "api"}},
+		{name: "page of selected", opts: ListOptions{State: StateReady, Offset: 1, Limit: 1}, want: []string{"web-2"}},
+		{name: "page of sorted", opts: ListOptions{SortBy: "name", Offset: 1, Limit: 2}, want: []string{"batch", "gpu"}},
+
+		{name: "nothing selected", opts: ListOptions{State: StateDeleting}, want: []string{}},
+	}
+
+	for _, test := range tests {
+		clusters, err := client.ListClusters(test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := clusterNames(clusters); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: listed %v, want %v", test.name, got, test.want)
+		}
+
+		it, err := client.IterateClusters(context.Background(), test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		got := []string{}
+		for it.Next() {
+			got = append(got, *it.Cluster().Name)
+		}
+		if it.Err() != nil {
+			t.Errorf("%s: iterating: %v", test.name, it.Err())
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("%s: closing: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: iterated %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestListOptionsErrors(t *testing.T) {
+
+	client := NewClient("admin", "secret", "http://ccp.invalid")
+
+	tests := []struct {
+		name string
+		list func() error
+	}{
+		{name: "negative offset", list: func() error { _, err := client.ListClusters(ListOptions{Offset: -1}); return err }},
+		{name: "negative limit", list: func() error { _, err := client.ListClusters(ListOptions{Limit: -1}); return err }},
+		{name: "bad selector", list: func() error { _, err := client.ListClusters(ListOptions{LabelSelector: "env<prod"}); return err }},
+		{name: "unknown sort field", list: func() error { _, err := client.ListClusters(ListOptions{SortBy: "colour"}); return err }},
+		{name: "state of users", list: func() error { _, err := client.ListUsers(ListOptions{State: StateReady}); return err }},
+		{name: "labels of profiles", list: func() error { _, err := client.ListACIProfiles(ListOptions{LabelSelector: "env"}); return err }},
+	}
+
+	for _, test := range tests {
+		if err := test.list(); err == nil {
+			t.Errorf("%s: succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestListEmpty(t *testing.T) {
+
+	for _, body := range []string{"[]", " [ ] ", "null", ""} {
+		srv := listServer(body)
+		client := NewClient("admin", "secret", srv.URL)
+
+		clusters, err := client.ListClusters(ListOptions{})
+		switch {
+		case err != nil:
+			t.Errorf("Body %q: %v", body, err)
+		case clusters == nil || len(clusters) != 0:
+			t.Errorf("Body %q: listed %v, want an empty list", body, clusters)
+		}
+
+		it, err := client.IterateClusters(context.Background(), ListOptions{})
+		if err != nil {
+			t.Errorf("Body %q: %v", body, err)
+		} else {
+			if it.Next() {
+				t.Errorf("Body %q: iterated %v, want nothing", body, it.Cluster())
+			}
+			if it.Err() != nil {
+				t.Errorf("Body %q: iterating: %v", body, it.Err())
+			}
+			it.Close()
+		}
+
+		srv.Close()
+	}
+}
+
+func TestListStreamErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		body string
+		opts ListOptions
+		// iterated is the clusters returned by an iterator before the error
+		iterated []string
+		wantErr  bool
+	}{
+		{name: "truncated", body: `[{"name": "web"}, {"name": "batch"}, {"name": `, iterated: []string{"web", "batch"}, wantErr: true},
+		{name: "bad element", body: `[{"name": "web"}, {"name": 7}]`, iterated: []string{"web"}, wantErr: true},
+		{name: "unterminated", body: `[{"name": "web"}`, iterated: []string{"web"}, wantErr: true},
+		{name: "not an array", body: `{"name": "web"}`, iterated: []string{}, wantErr: true},
+		{name: "sorted", body: `[{"name": "web"}, {"name": `, opts: ListOptions{SortBy: "name"}, iterated: []string{}, wantErr: true},
+		// The rest of the response is not decoded once the limit is reached
+		{name: "after the limit", body: `[{"name": "web"}, {"name": "batch"}, {"name": `, opts: ListOptions{Limit: 2}, iterated: []string{"web", "batch"}},
+	}
+
+	for _, test := range tests {
+		srv := listServer(test.body)
+		client := NewClient("admin", "secret", srv.URL)
+
+		clusters, err := client.ListClusters(test.opts)
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: listed %v, want an error", test.name, clusterNames(clusters))
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		}
+
+		got := []string{}
+		it, err := client.IterateClusters(context.Background(), test.opts)
+		if err == nil {
+			for it.Next() {
+				got = append(got, *it.Cluster().Name)
+			}
+			err = it.Err()
+			it.Close()
+		}
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%s: iterated without an error", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("%s: iterating: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.iterated) {
+			t.Errorf("%s: iterated %v, want %v", test.name, got, test.iterated)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestListAPIError(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient("admin", "secret", srv.URL)
+
+	if _, err := client.ListClusters(ListOptions{}); !isStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("ListClusters returned %v, want a 503 error", err)
+	}
+	if _, err := client.IterateClusters(context.Background(), ListOptions{}); !isStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("IterateClusters returned %v, want a 503 error", err)
+	}
+}
+
+func isStatus(err error, status int) bool {
+
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == status
+}
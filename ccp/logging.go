@@ -0,0 +1,105 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Logger receives structured records of the requests made by a Client. The arguments are alternating
+// keys and values, so a *slog.Logger from log/slog can be used directly
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// loggingTransport writes a record of every request to a Logger. It sits closest to the network so the
+// request is logged exactly as sent, after all middleware has run
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger Logger
+	bodies bool
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	args := []interface{}{
+		"method", req.Method,
+		"url", redactURL(req.URL),
+	}
+
+	if template := EndpointTemplate(req); template != "" {
+		args = append(args, "endpoint", template)
+	}
+
+	if t.bodies {
+		body, err := RequestBody(req)
+		if err == nil && len(body) > 0 {
+			args = append(args, "request_body", redactBody(req.Header.Get("Content-Type"), body))
+		}
+	}
+
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	args = append(args, "latency", time.Since(start))
+
+	if err != nil {
+		t.logger.Error("ccp request failed", append(args, "error", scrubRequestError(err, req))...)
+		return resp, err
+	}
+
+	args = append(args, "status", resp.StatusCode)
+
+	if t.bodies && resp.Body != nil {
+		body, readErr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if readErr != nil {
+			return nil, readErr
+		}
+		if len(body) > 0 {
+			args = append(args, "response_body", redactBody(resp.Header.Get("Content-Type"), body))
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		t.logger.Error("ccp request returned an error status", args...)
+	} else {
+		t.logger.Debug("ccp request", args...)
+	}
+
+	return resp, nil
+}
+
+// scrubRequestError removes the unredacted URL, which transport errors quote, from the error message
+func scrubRequestError(err error, req *http.Request) string {
+
+	msg := err.Error()
+
+	clean := redactURL(req.URL)
+	if full := req.URL.String(); full != clean {
+		msg = strings.Replace(msg, full, clean, -1)
+	}
+
+	return msg
+}
@@ -0,0 +1,161 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestIDHeader is the header set by RequestIDMiddleware when no other header is given
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// Middleware wraps the transport used for every request made by a Client. It sees the request as
+// sent, including the body and any session or token headers, and the response as received
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc allows a function to be used as an http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type endpointKey struct{}
+
+// Use adds middleware to the chain applied to every request. Middleware registered first runs first.
+// Each Middleware is called once to wrap the chain when the first request is sent, and again only if
+// Use is called later, since the chain is then rebuilt
+func (s *Client) Use(mw ...Middleware) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.middleware = append(s.middleware, mw...)
+	s.chain = nil
+}
+
+// EndpointTemplate returns the endpoint template of a request built by NewRequest, e.g.
+// "/clusters/{uuid}", which unlike the path is the same for every cluster
+func EndpointTemplate(req *http.Request) string {
+
+	template, _ := req.Context().Value(endpointKey{}).(string)
+
+	return template
+}
+
+// RequestBody returns a copy of the request body without consuming it
+func RequestBody(req *http.Request) ([]byte, error) {
+
+	if req.Body == nil || req.GetBody == nil {
+		return nil, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return ioutil.ReadAll(body)
+}
+
+// cloneRequest returns a shallow copy of the request with its own headers, since a RoundTripper must
+// not modify the request it is given
+func cloneRequest(req *http.Request) *http.Request {
+
+	clone := req.WithContext(req.Context())
+	clone.Header = make(http.Header, len(req.Header))
+
+	for k, v := range req.Header {
+		clone.Header[k] = append([]string(nil), v...)
+	}
+
+	return clone
+}
+
+// UserAgentMiddleware sets the User-Agent header on every request
+func UserAgentMiddleware(userAgent string) Middleware {
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = cloneRequest(req)
+			req.Header.Set("User-Agent", userAgent)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RequestIDMiddleware sets a unique ID on every request which does not already carry one, so calls can
+// be matched with the CCP logs. An empty header defaults to X-Request-ID and a nil generator to
+// random hex strings
+func RequestIDMiddleware(header string, generate func() string) Middleware {
+
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	if generate == nil {
+		generate = randomID
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) != "" {
+				return next.RoundTrip(req)
+			}
+			req = cloneRequest(req)
+			req.Header.Set(header, generate())
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func randomID() string {
+
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// LoggingMiddleware logs the method, path, status and duration of every request with logf, e.g.
+// log.Printf. Only the path is logged so query strings and bodies never reach the log
+func LoggingMiddleware(logf func(format string, v ...interface{})) Middleware {
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+			start := time.Now()
+
+			resp, err := next.RoundTrip(req)
+
+			if err != nil {
+				logf("ccp: %s %s failed after %v: %v", req.Method, req.URL.EscapedPath(), time.Since(start), err)
+				return resp, err
+			}
+
+			logf("ccp: %s %s %d (%v)", req.Method, req.URL.EscapedPath(), resp.StatusCode, time.Since(start))
+
+			return resp, nil
+		})
+	}
+}
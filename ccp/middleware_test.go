@@ -0,0 +1,164 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingLogger keeps the records it receives, formatted as the message followed by the arguments
+type recordingLogger struct {
+	records []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.records = append(l.records, fmt.Sprint(append([]interface{}{msg}, args...)...))
+}
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.records = append(l.records, fmt.Sprint(append([]interface{}{msg}, args...)...))
+}
+
+// tracing returns middleware which records the order it runs in, before and after the request
+func tracing(name string, mu *sync.Mutex, calls *[]string) Middleware {
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			*calls = append(*calls, name+" before")
+			mu.Unlock()
+
+			resp, err := next.RoundTrip(req)
+
+			mu.Lock()
+			*calls = append(*calls, name+" after")
+			mu.Unlock()
+
+			return resp, err
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var calls []string
+
+	client := NewClient("admin", "secret", srv.URL)
+	client.Use(tracing("first", &mu, &calls), tracing("second", &mu, &calls))
+	client.Use(tracing("third", &mu, &calls))
+
+	if _, err := client.GetLivenessHealth(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"first before", "second before", "third before", "third after", "second after", "first after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Middleware ran in the order %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareBuiltOnce(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	built := 0
+	requests := 0
+
+	// counting keeps the number of requests in the transport it returns, so it only counts every
+	// request when it is built once
+	counting := func(next http.RoundTripper) http.RoundTripper {
+		built++
+		count := 0
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			count++
+			requests = count
+			return next.RoundTrip(req)
+		})
+	}
+
+	client := NewClient("admin", "secret", srv.URL)
+	client.Use(counting)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetLivenessHealth(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if built != 1 || requests != 3 {
+		t.Errorf("Middleware was built %d times and counted %d requests, want 1 and 3", built, requests)
+	}
+
+	// Changing the logger takes effect without rebuilding the chain
+	logger := &recordingLogger{}
+	client.Logger = logger
+
+	if _, err := client.GetLivenessHealth(); err != nil {
+		t.Fatal(err)
+	}
+	if built != 1 || requests != 4 || len(logger.records) != 1 {
+		t.Errorf("After setting Logger the middleware was built %d times, counted %d requests and %d were logged, want 1, 4 and 1", built, requests, len(logger.records))
+	}
+
+	client.Use(UserAgentMiddleware("test"))
+
+	if _, err := client.GetLivenessHealth(); err != nil {
+		t.Fatal(err)
+	}
+	if built != 2 {
+		t.Errorf("Middleware was built %d times after Use, want 2", built)
+	}
+}
+
+func TestMiddlewareSeesAuthorization(t *testing.T) {
+
+	var header string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	client := NewClientWithToken(srv.URL, "abc")
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header.Get("Authorization")
+			return next.RoundTrip(req)
+		})
+	})
+
+	if _, err := client.GetLivenessHealth(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(header, "Bearer ") {
+		t.Errorf("Middleware saw Authorization %q, want the bearer token", header)
+	}
+}
@@ -0,0 +1,243 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OpenAPIDocument is an OpenAPI 3 document
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Security   []map[string][]string                   `json:"security,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL       string                           `json:"url"`
+	Variables map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+}
+
+type OpenAPIServerVariable struct {
+	Default string `json:"default"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	// Security, when set to an empty list, marks an operation which needs no authentication
+	Security *[]map[string][]string `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema                `json:"schemas"`
+	SecuritySchemes map[string]map[string]interface{} `json:"securitySchemes,omitempty"`
+}
+
+// apiOperation is a CCP endpoint called by a method of Client
+type apiOperation struct {
+	method string
+	// path is the endpoint template passed to NewRequest
+	path string
+	// id is the name of the Client method
+	id  string
+	tag string
+	// request and response are models of the JSON bodies, nil when there is none. A string response is
+	// plain text and loginForm is posted as a form
+	request  interface{}
+	response interface{}
+}
+
+// apiOperations are the endpoints covered by Client, in the order of the README
+var apiOperations = []apiOperation{
+	{method: "POST", path: "/system/login", id: "Login", tag: "System", request: loginForm{}},
+	{method: "GET", path: "/system/livenessHealth", id: "GetLivenessHealth", tag: "System", response: LivenessHealth{}},
+	{method: "GET", path: "/system/health", id: "GetHealth", tag: "System", response: Health{}},
+	{method: "GET", path: "/rbac", id: "GetRole", tag: "System", response: Role{}},
+	{method: "GET", path: "/ldap/setup", id: "GetLDAPSetup", tag: "LDAP", response: LDAPSetup{}},
+	{method: "GET", path: "/aci_profiles", id: "GetACIProfiles", tag: "ACIProfiles", response: []ACIProfile{}},
+
+	{method: "GET", path: "/localusers", id: "GetUsers", tag: "Users", response: []User{}},
+	{method: "POST", path: "/localusers", id: "AddUser", tag: "Users", request: User{}, response: User{}},
+	{method: "PATCH", path: "/localusers/{username}", id: "PatchUser", tag: "Users", request: User{}, response: User{}},
+	{method: "DELETE", path: "/localusers/{username}", id: "DeleteUser", tag: "Users"},
+
+	{method: "GET", path: "/providerclientconfigs", id: "GetProviderClientConfigs", tag: "ProviderClientConfigs", response: []ProviderClientConfig{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}", id: "GetProviderClientConfig", tag: "ProviderClientConfigs", response: ProviderClientConfig{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/clusters", id: "GetProviderClientConfigClusters", tag: "ProviderClientConfigs", response: []Cluster{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter", id: "GetProviderClientConfigVsphereDatacenter", tag: "ProviderClientConfigs", response: Vsphere{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/cluster", id: "GetProviderClientConfigVsphereDatacenterClusters", tag: "ProviderClientConfigs", response: Vsphere{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/vm", id: "GetProviderClientConfigVsphereDatacenterVMs", tag: "ProviderClientConfigs", response: Vsphere{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/network", id: "GetProviderClientConfigVsphereDatacenterNetworks", tag: "ProviderClientConfigs", response: Vsphere{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/datastore", id: "GetProviderClientConfigVsphereDatacenterDatastores", tag: "ProviderClientConfigs", response: Vsphere{}},
+	{method: "GET", path: "/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/cluster/{cluster}/pool", id: "GetProviderClientConfigVsphereDatacenterClusterPools", tag: "ProviderClientConfigs", response: Vsphere{}},
+
+	{method: "GET", path: "/clusters", id: "GetClusters", tag: "Clusters", response: []Cluster{}},
+	{method: "POST", path: "/clusters", id: "AddCluster", tag: "Clusters", request: Cluster{}, response: Cluster{}},
+	{method: "GET", path: "/clusters/{name}", id: "GetCluster", tag: "Clusters", response: Cluster{}},
+	{method: "PATCH", path: "/clusters/{uuid}", id: "PatchCluster", tag: "Clusters", request: Cluster{}, response: Cluster{}},
+	{method: "DELETE", path: "/clusters/{uuid}", id: "DeleteCluster", tag: "Clusters"},
+	{method: "GET", path: "/clusters/{uuid}/health", id: "GetClusterHealth", tag: "Clusters", response: ClusterHealth{}},
+	{method: "GET", path: "/clusters/{uuid}/authz", id: "GetClusterAuthz", tag: "Clusters", response: ClusterAuthz{}},
+	{method: "GET", path: "/clusters/{uuid}/dashboard", id: "GetClusterDashboard", tag: "Clusters", response: ""},
+	{method: "GET", path: "/clusters/{uuid}/env", id: "GetClusterEnv", tag: "Clusters", response: ""},
+	{method: "GET", path: "/clusters/{uuid}/helmcharts", id: "GetClusterHelmCharts", tag: "Clusters", response: HelmChart{}},
+}
+
+// sessionCookie is the name of the cookie set by a successful login
+const sessionCookie = "ccp_session"
+
+// loginForm is the form posted by Login
+type loginForm struct {
+	Username *string `json:"username" validate:"nonzero"`
+	Password *string `json:"password" validate:"nonzero" sensitive:"true"`
+}
+
+// OpenAPI returns an OpenAPI 3 document describing the CCP v2 endpoints called by Client, with the
+// request and response bodies generated from the model structs as JSONSchema does. Requests are
+// authenticated by the session cookie set by Login or by an API token
+func OpenAPI() *OpenAPIDocument {
+
+	g := &schemaGenerator{refPrefix: "#/components/schemas/", definitions: map[string]*Schema{}}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       "Cisco Container Platform API",
+			Description: "The CCP v2 endpoints covered by github.com/conmurphy/ccp-clientlibrary-go",
+			Version:     "v" + DefaultAPIVersion,
+		},
+		Servers: []OpenAPIServer{{
+			URL:       "https://{host}/" + DefaultAPIVersion,
+			Variables: map[string]OpenAPIServerVariable{"host": {Default: "ccp.example.com"}},
+		}},
+		// Either the session cookie or an API token authenticates a request
+		Security: []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {}}},
+		Paths:    map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			SecuritySchemes: map[string]map[string]interface{}{
+				"cookieAuth": {
+					"type":        "apiKey",
+					"in":          "cookie",
+					"name":        sessionCookie,
+					"description": "Session cookie set by POST /system/login",
+				},
+				"bearerAuth": {"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+
+	for _, op := range apiOperations {
+		operation := &OpenAPIOperation{
+			OperationID: op.id,
+			Tags:        []string{op.tag},
+			Responses:   map[string]*OpenAPIResponse{},
+		}
+
+		for _, segment := range strings.Split(op.path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				operation.Parameters = append(operation.Parameters, OpenAPIParameter{
+					Name:     strings.Trim(segment, "{}"),
+					In:       "path",
+					Required: true,
+					Schema:   &Schema{Type: "string"},
+				})
+			}
+		}
+
+		switch op.request.(type) {
+		case nil:
+		case loginForm:
+			operation.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content: map[string]OpenAPIMediaType{
+					"application/x-www-form-urlencoded": {Schema: g.structSchema(reflect.TypeOf(op.request))},
+				},
+			}
+			operation.Security = &[]map[string][]string{}
+		default:
+			operation.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  map[string]OpenAPIMediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(op.request))}},
+			}
+		}
+
+		switch op.response.(type) {
+		case nil:
+			operation.Responses["200"] = &OpenAPIResponse{Description: "Success"}
+		case string:
+			operation.Responses["200"] = &OpenAPIResponse{
+				Description: "Success",
+				Content:     map[string]OpenAPIMediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+			}
+		default:
+			operation.Responses["200"] = &OpenAPIResponse{
+				Description: "Success",
+				Content:     map[string]OpenAPIMediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(op.response))}},
+			}
+		}
+
+		if operation.Security == nil {
+			operation.Responses["401"] = &OpenAPIResponse{Description: "Not logged in"}
+		}
+		if strings.Contains(op.path, "{") {
+			operation.Responses["404"] = &OpenAPIResponse{Description: "Not found"}
+		}
+
+		if doc.Paths[op.path] == nil {
+			doc.Paths[op.path] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[op.path][strings.ToLower(op.method)] = operation
+	}
+
+	doc.Components.Schemas = g.definitions
+
+	return doc
+}
@@ -17,8 +17,6 @@ package ccp
 
 import (
 	"encoding/json"
-	"fmt"
-	"net/http"
 )
 
 type ProviderClientConfig struct {
@@ -30,7 +28,7 @@ type ProviderClientConfig struct {
 
 type Config struct {
 	IP       *string `json:"ip,omitempty"`
-	Port     *int64  `json:"po	rt,omitempty" `
+	Port     *int64  `json:"port,omitempty" `
 	Username *string `json:"username,omitempty"`
 }
 
@@ -45,9 +43,7 @@ type Vsphere struct {
 
 func (s *Client) GetProviderClientConfigs() ([]ProviderClientConfig, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -67,9 +63,7 @@ func (s *Client) GetProviderClientConfigs() ([]ProviderClientConfig, error) {
 
 func (s *Client) GetProviderClientConfig(clientUUID string) (*ProviderClientConfig, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID)
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}", clientUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -89,9 +83,7 @@ func (s *Client) GetProviderClientConfig(clientUUID string) (*ProviderClientConf
 
 func (s *Client) GetProviderClientConfigClusters(clientUUID string) ([]Cluster, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/clusters")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/clusters", clientUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -111,9 +103,7 @@ func (s *Client) GetProviderClientConfigClusters(clientUUID string) ([]Cluster,
 
 func (s *Client) GetProviderClientConfigVsphereDatacenter(clientUUID string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter", clientUUID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +123,7 @@ func (s *Client) GetProviderClientConfigVsphereDatacenter(clientUUID string) (*V
 
 func (s *Client) GetProviderClientConfigVsphereDatacenterClusters(clientUUID string, datacenter string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter/" + datacenter + "/cluster")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/cluster", clientUUID, datacenter), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -155,9 +143,7 @@ func (s *Client) GetProviderClientConfigVsphereDatacenterClusters(clientUUID str
 
 func (s *Client) GetProviderClientConfigVsphereDatacenterVMs(clientUUID string, datacenter string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter/" + datacenter + "/vm")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/vm", clientUUID, datacenter), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -177,9 +163,7 @@ func (s *Client) GetProviderClientConfigVsphereDatacenterVMs(clientUUID string,
 
 func (s *Client) GetProviderClientConfigVsphereDatacenterNetworks(clientUUID string, datacenter string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter/" + datacenter + "/network")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/network", clientUUID, datacenter), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -199,9 +183,7 @@ func (s *Client) GetProviderClientConfigVsphereDatacenterNetworks(clientUUID str
 
 func (s *Client) GetProviderClientConfigVsphereDatacenterDatastores(clientUUID string, datacenter string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter/" + datacenter + "/datastore")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/datastore", clientUUID, datacenter), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -221,9 +203,7 @@ func (s *Client) GetProviderClientConfigVsphereDatacenterDatastores(clientUUID s
 
 func (s *Client) GetProviderClientConfigVsphereDatacenterClusterPools(clientUUID string, datacenter string, cluster string) (*Vsphere, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/providerclientconfigs/" + clientUUID + "/vsphere/datacenter/" + datacenter + "/cluster/" + cluster + "/pool")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/providerclientconfigs/{uuid}/vsphere/datacenter/{datacenter}/cluster/{cluster}/pool", clientUUID, datacenter, cluster), nil)
 	if err != nil {
 		return nil, err
 	}
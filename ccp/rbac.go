@@ -17,8 +17,6 @@ package ccp
 
 import (
 	"encoding/json"
-	"fmt"
-	"net/http"
 )
 
 type Role struct {
@@ -27,9 +25,7 @@ type Role struct {
 
 func (s *Client) GetRole() (*Role, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/rbac")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/rbac"), nil)
 	if err != nil {
 		return nil, err
 	}
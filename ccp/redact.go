@@ -0,0 +1,207 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// redactedModels are the types searched for fields tagged sensitive:"true". Nested types are found
+// automatically, so only top level request and response types need to be listed
+var redactedModels = []interface{}{
+	Cluster{},
+	User{},
+	LDAPSetup{},
+	ACIProfile{},
+	ProviderClientConfig{},
+	Credentials{},
+}
+
+var (
+	sensitiveOnce sync.Once
+	sensitive     map[string]bool
+)
+
+// sensitiveKeys returns the lower cased JSON names of every field tagged sensitive:"true" in the models
+func sensitiveKeys() map[string]bool {
+
+	sensitiveOnce.Do(func() {
+		sensitive = map[string]bool{}
+		seen := map[reflect.Type]bool{}
+		for _, model := range redactedModels {
+			collectSensitive(reflect.TypeOf(model), seen)
+		}
+	})
+
+	return sensitive
+}
+
+func collectSensitive(t reflect.Type, seen map[reflect.Type]bool) {
+
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("sensitive") == "true" {
+			sensitive[strings.ToLower(jsonName(field))] = true
+		}
+		collectSensitive(field.Type, seen)
+	}
+}
+
+// jsonName returns the name used for the field when encoded as JSON
+func jsonName(field reflect.StructField) string {
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// isSensitive reports whether values under the JSON object or form key should be redacted
+func isSensitive(key string) bool {
+	return sensitiveKeys()[strings.ToLower(key)]
+}
+
+// RedactJSON returns a copy of a JSON document with the value of every sensitive field, such as
+// Cluster.SSHPassword or User.Password, replaced. Anything which is not a JSON object or array, such as
+// the kubeconfig returned by GetClusterEnv, is returned as nil since it cannot be safely inspected
+func RedactJSON(body []byte) []byte {
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil
+	}
+
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return nil
+	}
+
+	clean, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return nil
+	}
+
+	return clean
+}
+
+func redactValue(v interface{}) interface{} {
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if isSensitive(k) && child != nil {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+	}
+
+	return v
+}
+
+// redactForm returns a form encoded body with sensitive values replaced
+func redactForm(body []byte) []byte {
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil
+	}
+
+	return []byte(redactValues(form).Encode())
+}
+
+func redactValues(values url.Values) url.Values {
+
+	redactedValues := url.Values{}
+
+	for k, v := range values {
+		if isSensitive(k) {
+			redactedValues[k] = []string{redacted}
+		} else {
+			redactedValues[k] = v
+		}
+	}
+
+	return redactedValues
+}
+
+// redactURL returns the URL as a string with any user password and sensitive query parameters replaced
+func redactURL(u *url.URL) string {
+
+	clean := *u
+
+	if _, ok := u.User.Password(); ok {
+		clean.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if u.RawQuery != "" {
+		clean.RawQuery = redactValues(u.Query()).Encode()
+	}
+
+	return clean.String()
+}
+
+// redactBody redacts a request or response body according to its content type. Bodies which are
+// neither JSON nor a form are not shown since they cannot be inspected
+func redactBody(contentType string, body []byte) string {
+
+	if len(body) == 0 {
+		return ""
+	}
+
+	var clean []byte
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		clean = redactForm(body)
+	} else {
+		clean = RedactJSON(body)
+	}
+
+	if clean == nil {
+		return "(body not shown)"
+	}
+
+	return string(clean)
+}
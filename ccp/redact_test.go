@@ -0,0 +1,244 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const secret = "s3cret-value"
+
+// sensitiveModels hold a secret in every field the library tags sensitive:"true", along with a
+// field which must be kept
+var sensitiveModels = []struct {
+	name  string
+	model interface{}
+	kept  string
+}{
+	{name: "Cluster.SSHPassword", kept: "demo", model: Cluster{
+		Name:        String("demo"),
+		SSHPassword: String(secret),
+	}},
+	{name: "Cluster.CCPPrivateSSHKey", kept: "demo", model: Cluster{
+		Name:             String("demo"),
+		CCPPrivateSSHKey: String(secret),
+	}},
+	{name: "Cluster.HarborAdminServerPassword", kept: "demo", model: Cluster{
+		Name:                      String("demo"),
+		HarborAdminServerPassword: String(secret),
+	}},
+	{name: "provider client_config password", kept: "vcenter-admin", model: Cluster{
+		Deployer: &Deployer{
+			Provider: &Provider{
+				ClientConfig: &VsphereClientConfig{
+					Username: String("vcenter-admin"),
+					Password: String(secret),
+				},
+			},
+		},
+	}},
+	{name: "LDAPSetup.ServiceAccountPassword", kept: "ldap.example.com", model: LDAPSetup{
+		Server:                 String("ldap.example.com"),
+		ServiceAccountPassword: String(secret),
+	}},
+	{name: "User.Password", kept: "jsmith", model: User{
+		Username: String("jsmith"),
+		Password: String(secret),
+	}},
+	{name: "User.Token", kept: "jsmith", model: User{
+		Username: String("jsmith"),
+		Token:    String(secret),
+	}},
+	{name: "ACIProfile.APICPassword", kept: "apic.example.com", model: ACIProfile{
+		APICHosts:    String("apic.example.com"),
+		APICPassword: Int64(73519),
+	}},
+	{name: "Credentials.Password", kept: "admin", model: Credentials{
+		Username: "admin",
+		Password: secret,
+	}},
+	{name: "list of users", kept: "jsmith", model: []User{
+		{Username: String("jsmith"), Password: String(secret)},
+		{Username: String("jdoe"), Token: String(secret)},
+	}},
+}
+
+func TestRedactJSON(t *testing.T) {
+
+	for _, test := range sensitiveModels {
+		body, err := json.Marshal(test.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean := string(RedactJSON(body))
+
+		if strings.Contains(clean, secret) {
+			t.Errorf("%s: secret not redacted from %s", test.name, clean)
+		}
+		if !strings.Contains(clean, redacted) {
+			t.Errorf("%s: %s does not show the value was redacted", test.name, clean)
+		}
+		if !strings.Contains(clean, test.kept) {
+			t.Errorf("%s: %q was removed from %s", test.name, test.kept, clean)
+		}
+	}
+}
+
+func TestRedactJSONCaseInsensitive(t *testing.T) {
+
+	clean := string(RedactJSON([]byte(`{"password": "` + secret + `", "SSH_PASSWORD": "` + secret + `", "token": "` + secret + `"}`)))
+
+	if strings.Contains(clean, secret) {
+		t.Errorf("Secret not redacted from %s", clean)
+	}
+}
+
+func TestRedactJSONNotJSON(t *testing.T) {
+
+	for _, body := range []string{"apiVersion: v1\npassword: " + secret, `"` + secret + `"`, "{not json"} {
+		if clean := RedactJSON([]byte(body)); clean != nil {
+			t.Errorf("RedactJSON(%q) = %q, want nil", body, clean)
+		}
+	}
+}
+
+// TestSensitiveFieldsCovered pins the keys found from the sensitive:"true" tags, so a tag which is
+// removed or no longer found is noticed
+func TestSensitiveFieldsCovered(t *testing.T) {
+
+	want := map[string]bool{
+		"ssh_password":                 true,
+		"ccp_private_ssh_key":          true,
+		"harbor_admin_server_password": true,
+		"password":                     true,
+		"serviceaccountpassword":       true,
+		"token":                        true,
+		"apic_password":                true,
+	}
+
+	if got := sensitiveKeys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sensitive keys are %v, want %v", got, want)
+	}
+}
+
+func TestRedactForm(t *testing.T) {
+
+	form := url.Values{
+		"username":               {"admin"},
+		"password":               {secret},
+		"ServiceAccountPassword": {secret},
+		"apic_password":          {secret},
+		"ssh_password":           {secret},
+	}
+
+	clean := string(redactForm([]byte(form.Encode())))
+
+	if strings.Contains(clean, secret) {
+		t.Errorf("Secret not redacted from %s", clean)
+	}
+	if !strings.Contains(clean, "username=admin") {
+		t.Errorf("Username removed from %s", clean)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+
+	tests := []struct {
+		url  string
+		kept string
+	}{
+		{url: "https://ccp.example.com/2/system/login?username=admin&password=" + secret, kept: "username=admin"},
+		{url: "https://ccp.example.com/2/localusers?Token=" + secret, kept: "/2/localusers"},
+		{url: "https://ccp.example.com/2/clusters?harbor_admin_server_password=" + secret + "&name=demo", kept: "name=demo"},
+		{url: "https://ccp.example.com/2/aci?apic_password=" + url.QueryEscape(secret+"&x"), kept: "/2/aci"},
+		{url: "https://admin:" + secret + "@ccp.example.com/2/clusters", kept: "admin"},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean := redactURL(u)
+
+		if strings.Contains(clean, secret) {
+			t.Errorf("Secret not redacted from %s", clean)
+		}
+		if !strings.Contains(clean, test.kept) {
+			t.Errorf("%q was removed from %s", test.kept, clean)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+
+	tests := []struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		{contentType: "application/json", body: `{"Password":"` + secret + `"}`, want: `{"Password":"[REDACTED]"}`},
+		{contentType: "application/x-www-form-urlencoded", body: "password=" + secret, want: "password=%5BREDACTED%5D"},
+		{contentType: "application/yaml", body: "password: " + secret, want: "(body not shown)"},
+		{contentType: "application/json", body: "", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := redactBody(test.contentType, []byte(test.body)); got != test.want {
+			t.Errorf("redactBody(%s, %q) = %q, want %q", test.contentType, test.body, got, test.want)
+		}
+	}
+}
+
+func TestLoggerRedactsBodies(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"username": "jsmith", "Token": %q}`, secret)
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+
+	client := NewClient("admin", secret, srv.URL)
+	client.Logger = logger
+	client.LogBodies = true
+
+	if err := client.Login(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AddUser(&User{Username: String("jsmith"), Password: String(secret), Role: String("Developer")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logger.records) != 2 {
+		t.Fatalf("Logged %d records, want 2", len(logger.records))
+	}
+	for _, record := range logger.records {
+		if strings.Contains(record, secret) {
+			t.Errorf("Secret not redacted from %s", record)
+		}
+	}
+}
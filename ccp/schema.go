@@ -0,0 +1,169 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+//go:generate go run ../internal/schemagen -out ../schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON Schema version of the schemas returned by JSONSchema
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document, and also the form of the schemas in an OpenAPI document
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// schemaModels are the models described by Schemas
+var schemaModels = []interface{}{
+	Cluster{},
+	User{},
+	ACIProfile{},
+	LDAPSetup{},
+	ProviderClientConfig{},
+}
+
+// Schemas returns the JSON Schema of each model sent to CCP, keyed by its type name, e.g. "Cluster"
+func Schemas() (map[string]*Schema, error) {
+
+	schemas := map[string]*Schema{}
+
+	for _, model := range schemaModels {
+		schema, err := JSONSchema(model)
+		if err != nil {
+			return nil, err
+		}
+		schemas[schema.Title] = schema
+	}
+
+	return schemas, nil
+}
+
+// JSONSchema returns the JSON Schema of a model struct, such as Cluster{}, generated from its fields.
+// Properties are named by their JSON tags, fields tagged validate:"nonzero" are required and sensitive
+// fields, such as passwords, have the password format. Nested structs are kept in definitions and
+// unknown properties are not allowed, matching LoadClusterSpec
+func JSONSchema(model interface{}) (*Schema, error) {
+
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Cannot generate a schema for %T, a struct is required", model)
+	}
+
+	g := &schemaGenerator{refPrefix: "#/definitions/", strict: true, definitions: map[string]*Schema{}}
+
+	schema := g.structSchema(t)
+	schema.Schema = JSONSchemaDraft
+	schema.Title = t.Name()
+
+	delete(g.definitions, t.Name())
+	if len(g.definitions) > 0 {
+		schema.Definitions = g.definitions
+	}
+
+	return schema, nil
+}
+
+type schemaGenerator struct {
+	refPrefix string
+	// strict disallows properties which are not fields of the struct
+	strict      bool
+	definitions map[string]*Schema
+}
+
+// schemaOf returns the schema of a type, with structs given as a reference to their definition
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; !ok {
+			// Added before the fields so a struct containing itself refers to its definition
+			g.definitions[t.Name()] = &Schema{}
+			*g.definitions[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: g.refPrefix + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	if g.strict {
+		schema.AdditionalProperties = Bool(false)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		property := g.schemaOf(field.Type)
+
+		if field.Tag.Get("sensitive") == "true" && property.Type == "string" {
+			property.Format = "password"
+		}
+
+		schema.Properties[name] = property
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "nonzero" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	return schema
+}
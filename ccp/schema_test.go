@@ -0,0 +1,205 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSchemaFilesUpToDate checks the files written by go generate match the models
+func TestSchemaFilesUpToDate(t *testing.T) {
+
+	schemas, err := Schemas()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]interface{}{"openapi.json": OpenAPI()}
+	for name, schema := range schemas {
+		files[strings.ToLower(name)+".schema.json"] = schema
+	}
+
+	for name, v := range files {
+		want, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ioutil.ReadFile(filepath.Join("..", "schema", name))
+		if err != nil {
+			t.Errorf("%v, run go generate ./ccp", err)
+			continue
+		}
+
+		if !bytes.Equal(bytes.TrimSpace(got), want) {
+			t.Errorf("schema/%s is out of date, run go generate ./ccp", name)
+		}
+	}
+}
+
+// TestOpenAPICoversEndpoints checks every endpoint requested by the client is in the OpenAPI document
+func TestOpenAPICoversEndpoints(t *testing.T) {
+
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := OpenAPI().Paths
+	found := 0
+
+	for _, file := range pkgs["ccp"].Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !strings.HasPrefix(sel.Sel.Name, "NewRequest") || len(call.Args) < 3 {
+				return true
+			}
+
+			// NewRequestWithContext takes the context first
+			args := call.Args[len(call.Args)-3:]
+
+			method, ok := stringLiteral(args[0])
+			if !ok {
+				return true
+			}
+			e, ok := args[1].(*ast.CallExpr)
+			if !ok || len(e.Args) == 0 {
+				return true
+			}
+			path, ok := stringLiteral(e.Args[0])
+			if !ok {
+				return true
+			}
+
+			found++
+			if paths[path][strings.ToLower(method)] == nil {
+				t.Errorf("%s: %s %s is missing from apiOperations", fset.Position(call.Pos()), method, path)
+			}
+			return true
+		})
+	}
+
+	if found == 0 {
+		t.Fatal("No requests found in the ccp package")
+	}
+}
+
+// TestOpenAPIOperationsMatchClient calls every method of the client and checks each operation of
+// apiOperations is requested by the method it is named after, and that every request is described
+func TestOpenAPIOperationsMatchClient(t *testing.T) {
+
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	vars := goldenVars{
+		LoginUsername:  "admin",
+		Username:       "jdoe",
+		ClusterName:    "demo",
+		ClusterUUID:    "1234",
+		ProviderUUID:   "5678",
+		Datacenter:     "dc-1",
+		VsphereCluster: "cluster-1",
+	}
+
+	client := NewClient(vars.LoginUsername, "secret", srv.URL)
+
+	// The requests made by each method
+	sent := map[string][]string{}
+	for _, c := range goldenCases {
+		requests = nil
+		c.call(client, vars)
+		sent[c.name] = requests
+	}
+
+	operations := map[string]*regexp.Regexp{}
+	for _, op := range apiOperations {
+		segments := strings.Split(op.path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, "{") {
+				segments[i] = "[^/]+"
+			} else {
+				segments[i] = regexp.QuoteMeta(segment)
+			}
+		}
+		operations[op.id] = regexp.MustCompile("^" + op.method + " /" + DefaultAPIVersion + strings.Join(segments, "/") + "$")
+	}
+
+	for _, op := range apiOperations {
+		calls, ok := sent[op.id]
+		if !ok {
+			t.Errorf("%s %s: no method named %s is called by goldenCases", op.method, op.path, op.id)
+			continue
+		}
+		matched := false
+		for _, call := range calls {
+			matched = matched || operations[op.id].MatchString(call)
+		}
+		if !matched {
+			t.Errorf("%s %s: %s sent %v", op.method, op.path, op.id, calls)
+		}
+	}
+
+	for name, calls := range sent {
+		for _, call := range calls {
+			described := false
+			for _, pattern := range operations {
+				described = described || pattern.MatchString(call)
+			}
+			if !described {
+				t.Errorf("%s sent %s, which is missing from apiOperations", name, call)
+			}
+		}
+	}
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+
+	return s, err == nil
+}
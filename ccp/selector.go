@@ -0,0 +1,262 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelSelector selects clusters by their labels, using the syntax of Kubernetes label selectors. A
+// selector is a comma separated list of requirements which must all match
+//
+//	env=prod            env is prod, also written env==prod
+//	env!=prod           env is not prod, or there is no env label
+//	team in (a,b)       team is a or b
+//	team notin (a,b)    team is neither a nor b, or there is no team label
+//	gpu                 there is a gpu label
+//	!gpu                there is no gpu label
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+type labelRequirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// Label selector operators
+const (
+	selectorEquals       = "="
+	selectorNotEquals    = "!="
+	selectorIn           = "in"
+	selectorNotIn        = "notin"
+	selectorExists       = "exists"
+	selectorDoesNotExist = "!"
+)
+
+// ParseLabelSelector parses a label selector. An empty selector selects every cluster
+func ParseLabelSelector(selector string) (*LabelSelector, error) {
+
+	parsed := &LabelSelector{}
+
+	for _, part := range splitRequirements(selector) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			if strings.TrimSpace(selector) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("Invalid label selector %q: empty requirement", selector)
+		}
+
+		r, err := parseRequirement(part)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid label selector %q: %v", selector, err)
+		}
+		parsed.requirements = append(parsed.requirements, r)
+	}
+
+	return parsed, nil
+}
+
+// splitRequirements splits a selector on the commas which are not within the values of in and notin
+func splitRequirements(selector string) []string {
+
+	var parts []string
+
+	depth, start := 0, 0
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, selector[start:])
+}
+
+func parseRequirement(s string) (labelRequirement, error) {
+
+	if strings.HasPrefix(s, "!") {
+		key := strings.TrimSpace(s[1:])
+		if err := validLabelToken(key, "key"); err != nil {
+			return labelRequirement{}, err
+		}
+		return labelRequirement{key: key, operator: selectorDoesNotExist}, nil
+	}
+
+	end := strings.IndexAny(s, " \t=!(")
+	if end < 0 {
+		end = len(s)
+	}
+
+	r := labelRequirement{key: s[:end]}
+	if err := validLabelToken(r.key, "key"); err != nil {
+		return r, err
+	}
+
+	rest := strings.TrimSpace(s[end:])
+
+	switch {
+	case rest == "":
+		r.operator = selectorExists
+		return r, nil
+	case strings.HasPrefix(rest, "=="):
+		r.operator, rest = selectorEquals, rest[2:]
+	case strings.HasPrefix(rest, "="):
+		r.operator, rest = selectorEquals, rest[1:]
+	case strings.HasPrefix(rest, "!="):
+		r.operator, rest = selectorNotEquals, rest[2:]
+	case strings.HasPrefix(rest, selectorNotIn):
+		r.operator, rest = selectorNotIn, rest[len(selectorNotIn):]
+	case strings.HasPrefix(rest, selectorIn):
+		r.operator, rest = selectorIn, rest[len(selectorIn):]
+	default:
+		return r, fmt.Errorf("unknown operator in %q", s)
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	if r.operator == selectorEquals || r.operator == selectorNotEquals {
+		if err := validLabelToken(rest, "value"); err != nil && rest != "" {
+			return r, err
+		}
+		r.values = []string{rest}
+		return r, nil
+	}
+
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return r, fmt.Errorf("the values of %s %s must be within parentheses", r.key, r.operator)
+	}
+
+	values := strings.TrimSpace(rest[1 : len(rest)-1])
+	if values == "" {
+		return r, fmt.Errorf("%s %s needs at least one value", r.key, r.operator)
+	}
+
+	for _, value := range strings.Split(values, ",") {
+		value = strings.TrimSpace(value)
+		if err := validLabelToken(value, "value"); err != nil && value != "" {
+			return r, err
+		}
+		r.values = append(r.values, value)
+	}
+
+	return r, nil
+}
+
+// validLabelToken checks a label key or value holds only the characters allowed by Kubernetes
+func validLabelToken(s, what string) error {
+
+	if s == "" {
+		return fmt.Errorf("missing label %s", what)
+	}
+
+	for _, ch := range s {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-' || ch == '_' || ch == '.' || ch == '/':
+		default:
+			return fmt.Errorf("label %s %q contains %q", what, s, ch)
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the labels satisfy every requirement of the selector
+func (sel *LabelSelector) Matches(labels map[string]string) bool {
+
+	if sel == nil {
+		return true
+	}
+
+	for _, r := range sel.requirements {
+		value, ok := labels[r.key]
+
+		switch r.operator {
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorDoesNotExist:
+			if ok {
+				return false
+			}
+		case selectorEquals, selectorIn:
+			if !ok || !containsString(r.values, value) {
+				return false
+			}
+		case selectorNotEquals, selectorNotIn:
+			if ok && containsString(r.values, value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// String returns the selector in its canonical form, or "" for a nil selector
+func (sel *LabelSelector) String() string {
+
+	if sel == nil {
+		return ""
+	}
+
+	parts := make([]string, len(sel.requirements))
+
+	for i, r := range sel.requirements {
+		switch r.operator {
+		case selectorExists:
+			parts[i] = r.key
+		case selectorDoesNotExist:
+			parts[i] = "!" + r.key
+		case selectorEquals, selectorNotEquals:
+			parts[i] = r.key + r.operator + r.values[0]
+		default:
+			parts[i] = fmt.Sprintf("%s %s (%s)", r.key, r.operator, strings.Join(r.values, ","))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ClusterLabels returns the labels of a cluster as a map
+func ClusterLabels(cluster *Cluster) map[string]string {
+
+	labels := map[string]string{}
+
+	if cluster == nil || cluster.Labels == nil {
+		return labels
+	}
+
+	for _, label := range *cluster.Labels {
+		if label.Key != nil {
+			labels[*label.Key] = derefString(label.Value)
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,125 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+
+	tests := []struct {
+		selector string
+		// want is the canonical form, ignored when wantErr is set
+		want    string
+		wantErr bool
+	}{
+		{selector: "", want: ""},
+		{selector: "  ", want: ""},
+		{selector: "env=prod", want: "env=prod"},
+		{selector: "env==prod", want: "env=prod"},
+		{selector: " env = prod ", want: "env=prod"},
+		{selector: "env!=prod", want: "env!=prod"},
+		{selector: "env=", want: "env="},
+		{selector: "team in (a,b)", want: "team in (a,b)"},
+		{selector: "team in(a, b)", want: "team in (a,b)"},
+		{selector: "team notin (a)", want: "team notin (a)"},
+		{selector: "team in (a,)", want: "team in (a,)"},
+		{selector: "gpu", want: "gpu"},
+		{selector: "!gpu", want: "!gpu"},
+		{selector: "env=prod,team in (a,b),!gpu", want: "env=prod,team in (a,b),!gpu"},
+		{selector: "example.com/tier=web", want: "example.com/tier=web"},
+
+		{selector: "x in ()", wantErr: true},
+		{selector: "x in ( )", wantErr: true},
+		{selector: "x notin ()", wantErr: true},
+		{selector: "x in a,b", wantErr: true},
+		{selector: "x in (a", wantErr: true},
+		{selector: "env=prod,", wantErr: true},
+		{selector: ",env=prod", wantErr: true},
+		{selector: "env=prod,,team=a", wantErr: true},
+		{selector: "=prod", wantErr: true},
+		{selector: "!", wantErr: true},
+		{selector: "env<prod", wantErr: true},
+		{selector: "env=pr od", wantErr: true},
+		{selector: "env=prod!", wantErr: true},
+		{selector: "team in (a b)", wantErr: true},
+		{selector: "team exists", wantErr: true},
+	}
+
+	for _, test := range tests {
+		sel, err := ParseLabelSelector(test.selector)
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("ParseLabelSelector(%q) = %q, want an error", test.selector, sel)
+		case !test.wantErr && err != nil:
+			t.Errorf("ParseLabelSelector(%q): %v", test.selector, err)
+		case !test.wantErr && sel.String() != test.want:
+			t.Errorf("ParseLabelSelector(%q) = %q, want %q", test.selector, sel, test.want)
+		}
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+
+	labels := map[string]string{"env": "prod", "team": "web", "empty": ""}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env=dev", want: false},
+		{selector: "env!=dev", want: true},
+		{selector: "missing!=dev", want: true},
+		{selector: "env!=prod", want: false},
+		{selector: "team in (api,web)", want: true},
+		{selector: "team in (api)", want: false},
+		{selector: "missing in (api)", want: false},
+		{selector: "team notin (api)", want: true},
+		{selector: "team notin (web)", want: false},
+		{selector: "missing notin (web)", want: true},
+		{selector: "env", want: true},
+		{selector: "missing", want: false},
+		{selector: "!missing", want: true},
+		{selector: "!env", want: false},
+		{selector: "empty=", want: true},
+		{selector: "empty in (a,)", want: true},
+		{selector: "env=prod,team=web", want: true},
+		{selector: "env=prod,team=api", want: false},
+	}
+
+	for _, test := range tests {
+		sel, err := ParseLabelSelector(test.selector)
+		if err != nil {
+			t.Errorf("ParseLabelSelector(%q): %v", test.selector, err)
+			continue
+		}
+		if got := sel.Matches(labels); got != test.want {
+			t.Errorf("%q matches %v = %v, want %v", test.selector, labels, got, test.want)
+		}
+	}
+
+	var nilSelector *LabelSelector
+	if !nilSelector.Matches(labels) {
+		t.Error("A nil selector does not match every cluster")
+	}
+	if s := nilSelector.String(); s != "" {
+		t.Errorf("A nil selector is %q, want an empty selector", s)
+	}
+}
@@ -0,0 +1,132 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+)
+
+// The interfaces below group the operations of Client by resource so that code using the library can
+// depend on only what it needs and substitute a mock, such as the one in the ccpmock package, in tests.
+// After changing them run go generate ./ccpmock to update the mock
+
+// SystemService covers login and the health of the CCP control plane
+type SystemService interface {
+	Login(client *Client) error
+	GetLivenessHealth() (*LivenessHealth, error)
+	GetLivenessHealthContext(ctx context.Context) (*LivenessHealth, error)
+	GetHealth() (*Health, error)
+	GetHealthContext(ctx context.Context) (*Health, error)
+	CheckControlPlane(ctx context.Context) *ControlPlaneStatus
+}
+
+// UserService covers local users and their API tokens
+type UserService interface {
+	GetUsers() ([]User, error)
+	ListUsers(opts ListOptions) ([]User, error)
+	ListUsersContext(ctx context.Context, opts ListOptions) ([]User, error)
+	GetUser(username string) (*User, error)
+	AddUser(user *User) (*User, error)
+	AddUserContext(ctx context.Context, user *User) (*User, error)
+	PatchUser(user *User) (*User, error)
+	PatchUserContext(ctx context.Context, user *User) (*User, error)
+	DeleteUser(username string) error
+	DeleteUserContext(ctx context.Context, username string) error
+}
+
+// ClusterService covers tenant clusters
+type ClusterService interface {
+	GetClusters() ([]Cluster, error)
+	GetClustersContext(ctx context.Context) ([]Cluster, error)
+	ListClusters(opts ListOptions) ([]Cluster, error)
+	ListClustersContext(ctx context.Context, opts ListOptions) ([]Cluster, error)
+	IterateClusters(ctx context.Context, opts ListOptions) (*ClusterIterator, error)
+	WatchClusters(ctx context.Context, opts WatchOptions) <-chan ClusterEvent
+	GetCluster(clusterName string) (*Cluster, error)
+	GetClusterContext(ctx context.Context, clusterName string) (*Cluster, error)
+	GetClusterHealth(clusterUUID string) (*ClusterHealth, error)
+	GetClusterHealthContext(ctx context.Context, clusterUUID string) (*ClusterHealth, error)
+	GetClusterAuthz(clusterUUID string) (*ClusterAuthz, error)
+	GetClusterAuthzContext(ctx context.Context, clusterUUID string) (*ClusterAuthz, error)
+	SetClusterAuthz(clusterUUID string, principals []string) (*ClusterAuthz, error)
+	SetClusterAuthzContext(ctx context.Context, clusterUUID string, principals []string) (*ClusterAuthz, error)
+	GrantClusterAccess(clusterUUID, principal string) (*ClusterAuthz, error)
+	GrantClusterAccessContext(ctx context.Context, clusterUUID, principal string) (*ClusterAuthz, error)
+	RevokeClusterAccess(clusterUUID, principal string) (*ClusterAuthz, error)
+	RevokeClusterAccessContext(ctx context.Context, clusterUUID, principal string) (*ClusterAuthz, error)
+	GetClusterDashboard(clusterUUID string) (*string, error)
+	GetClusterEnv(clusterUUID string) (*string, error)
+	GetClusterHelmCharts(clusterUUID string) (*HelmChart, error)
+	AddCluster(cluster *Cluster) (*Cluster, error)
+	AddClusterContext(ctx context.Context, cluster *Cluster) (*Cluster, error)
+	AddClusterBasic(cluster *Cluster) (*Cluster, error)
+	AddClusterBasicContext(ctx context.Context, cluster *Cluster) (*Cluster, error)
+	PatchCluster(cluster *Cluster) (*Cluster, error)
+	PatchClusterContext(ctx context.Context, cluster *Cluster) (*Cluster, error)
+	DeleteCluster(uuid string) error
+	DeleteClusterContext(ctx context.Context, uuid string) error
+	PlanCluster(ctx context.Context, desired *Cluster) (*ClusterPlan, error)
+	ApplyCluster(ctx context.Context, desired *Cluster) (*ClusterPlan, error)
+	ExportClusterTemplate(ctx context.Context, clusterName string, overrides *TemplateOverrides) (*Cluster, error)
+	SetClusterLabels(ctx context.Context, clusterName string, labels map[string]string) (*Cluster, error)
+	AddClusterLabel(ctx context.Context, clusterName, key, value string) (*Cluster, error)
+	RemoveClusterLabel(ctx context.Context, clusterName, key string) (*Cluster, error)
+}
+
+// ProviderConfigService covers provider client configs and browsing the vSphere inventory behind them
+type ProviderConfigService interface {
+	GetProviderClientConfigs() ([]ProviderClientConfig, error)
+	ListProviderClientConfigs(opts ListOptions) ([]ProviderClientConfig, error)
+	ListProviderClientConfigsContext(ctx context.Context, opts ListOptions) ([]ProviderClientConfig, error)
+	GetProviderClientConfig(clientUUID string) (*ProviderClientConfig, error)
+	GetProviderClientConfigClusters(clientUUID string) ([]Cluster, error)
+	GetProviderClientConfigVsphereDatacenter(clientUUID string) (*Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterClusters(clientUUID string, datacenter string) (*Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterVMs(clientUUID string, datacenter string) (*Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterNetworks(clientUUID string, datacenter string) (*Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterDatastores(clientUUID string, datacenter string) (*Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterClusterPools(clientUUID string, datacenter string, cluster string) (*Vsphere, error)
+}
+
+// ACIProfileService covers ACI profiles
+type ACIProfileService interface {
+	GetACIProfiles() ([]ACIProfile, error)
+	ListACIProfiles(opts ListOptions) ([]ACIProfile, error)
+	ListACIProfilesContext(ctx context.Context, opts ListOptions) ([]ACIProfile, error)
+}
+
+// LDAPService covers the LDAP configuration
+type LDAPService interface {
+	GetLDAPSetup() (*LDAPSetup, error)
+}
+
+// RBACService covers the role of the logged in user
+type RBACService interface {
+	GetRole() (*Role, error)
+}
+
+// API is every operation of the CCP API covered by this library
+type API interface {
+	SystemService
+	UserService
+	ClusterService
+	ProviderConfigService
+	ACIProfileService
+	LDAPService
+	RBACService
+}
+
+var _ API = (*Client)(nil)
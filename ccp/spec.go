@@ -0,0 +1,474 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Formats of cluster spec files
+const (
+	SpecYAML = "yaml"
+	SpecJSON = "json"
+)
+
+// envPattern matches ${NAME}, and $${NAME} which is left in place as ${NAME}
+var envPattern = regexp.MustCompile(`\$(\$)?\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// SpecError is a problem found at a line of a cluster spec file
+type SpecError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SpecError) Error() string {
+
+	if e.File == "" {
+		return fmt.Sprintf("line %d column %d: %s", e.Line, e.Column, e.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// SpecErrors is every problem found in a cluster spec file
+type SpecErrors []*SpecError
+
+func (e SpecErrors) Error() string {
+
+	msgs := []string{}
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// specFormat returns the format of a spec file from its extension, defaulting to YAML
+func specFormat(path string) string {
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return SpecJSON
+	}
+
+	return SpecYAML
+}
+
+// LoadClusterSpec reads a cluster from a YAML or JSON file, chosen by the .json, .yaml or .yml extension.
+// Every ${NAME} in a value is replaced with the environment variable NAME, so secrets such as SSHKey or
+// HarborAdminServerPassword need not be kept in the file, and $${NAME} is read as ${NAME}. The file is
+// checked against the fields of Cluster, and every unknown field, value of the wrong type or unset
+// variable is reported in SpecErrors with its line number
+func LoadClusterSpec(path string) (*Cluster, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseClusterSpec(path, data)
+}
+
+// ParseClusterSpec is LoadClusterSpec for a spec already read. name is used in errors and its extension
+// chooses the format
+func ParseClusterSpec(name string, data []byte) (*Cluster, error) {
+
+	if specFormat(name) == SpecJSON {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				line, column := position(data, syntaxErr.Offset-1)
+				return nil, SpecErrors{{File: name, Line: line, Column: column, Message: syntaxErr.Error()}}
+			}
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	// JSON is also YAML, so both are parsed the same way to keep line numbers
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s: cluster spec is empty", name)
+	}
+
+	root := doc.Content[0]
+
+	var errs SpecErrors
+
+	substituteEnv(name, root, reflect.TypeOf(Cluster{}), &errs)
+	checkSpec(name, root, reflect.TypeOf(Cluster{}), "", &errs)
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			return errs[i].Line < errs[j].Line || (errs[i].Line == errs[j].Line && errs[i].Column < errs[j].Column)
+		})
+		return nil, errs
+	}
+
+	var v interface{}
+
+	if err := root.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	var cluster Cluster
+
+	if err := json.Unmarshal(j, &cluster); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return &cluster, nil
+}
+
+// position returns the line and column of a byte offset
+func position(data []byte, offset int64) (int, int) {
+
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
+
+// substituteEnv replaces ${NAME} in every scalar value with the environment variable NAME. t is the
+// type the value is decoded into, which decides how a plain value is read after the substitution
+func substituteEnv(name string, node *yaml.Node, t reflect.Type, errs *SpecErrors) {
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for _, child := range node.Content {
+			substituteEnv(name, child, elem, errs)
+		}
+	case yaml.MappingNode:
+		var fields map[string]reflect.Type
+		if t != nil && t.Kind() == reflect.Struct {
+			fields = map[string]reflect.Type{}
+			for i := 0; i < t.NumField(); i++ {
+				fields[jsonName(t.Field(i))] = t.Field(i).Type
+			}
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			var value reflect.Type
+			switch {
+			case fields != nil:
+				value = fields[node.Content[i-1].Value]
+			case t != nil && t.Kind() == reflect.Map:
+				value = t.Elem()
+			}
+			substituteEnv(name, node.Content[i], value, errs)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return
+		}
+		node.Value = envPattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+			groups := envPattern.FindStringSubmatch(match)
+			if groups[1] != "" {
+				return match[1:]
+			}
+			value, ok := os.LookupEnv(groups[2])
+			if !ok {
+				*errs = append(*errs, &SpecError{File: name, Line: node.Line, Column: node.Column,
+					Message: fmt.Sprintf("environment variable %s is not set", groups[2])})
+			}
+			return value
+		})
+		// A value substituted into a string field stays a string, so a password of 12345678 or null is
+		// kept as written. Other plain values are resolved again, so workers: ${WORKERS} is read as a
+		// number
+		switch {
+		case t != nil && t.Kind() == reflect.String:
+			node.Tag = "!!str"
+		case node.Style == 0:
+			node.Tag = ""
+		}
+	}
+}
+
+// checkSpec reports every value of node which cannot be decoded into the type t
+func checkSpec(name string, node *yaml.Node, t reflect.Type, field string, errs *SpecErrors) {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+
+	fail := func(n *yaml.Node, format string, args ...interface{}) {
+		*errs = append(*errs, &SpecError{File: name, Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	describe := "the cluster"
+	if field != "" {
+		describe = field
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			fail(node, "%s must be a mapping", describe)
+			return
+		}
+		fields := map[string]reflect.StructField{}
+		for i := 0; i < t.NumField(); i++ {
+			fields[jsonName(t.Field(i))] = t.Field(i)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			f, ok := fields[key.Value]
+			if !ok {
+				fail(key, "unknown field %s in %s", key.Value, t.Name())
+				continue
+			}
+			checkSpec(name, value, f.Type, joinField(field, key.Value), errs)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			fail(node, "%s must be a list", describe)
+			return
+		}
+		for i, child := range node.Content {
+			checkSpec(name, child, t.Elem(), fmt.Sprintf("%s[%d]", field, i), errs)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			fail(node, "%s must be a mapping", describe)
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkSpec(name, node.Content[i], t.Key(), describe+" key", errs)
+			checkSpec(name, node.Content[i+1], t.Elem(), joinField(field, node.Content[i].Value), errs)
+		}
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" {
+			fail(node, "%s must be a string, quote the value to use it as text", describe)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			fail(node, "%s must be a whole number, found %q", describe, node.Value)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" || strings.HasPrefix(node.Value, "-") {
+			fail(node, "%s must be a positive whole number, found %q", describe, node.Value)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.ShortTag() != "!!int" && node.ShortTag() != "!!float") {
+			fail(node, "%s must be a number, found %q", describe, node.Value)
+		}
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			fail(node, "%s must be true or false, found %q", describe, node.Value)
+		}
+
+	case reflect.Interface:
+		// Any value can be decoded into interface{}
+
+	default:
+		fail(node, "%s has the type %s, which cannot be read from a cluster spec", describe, t)
+	}
+}
+
+func joinField(parent, field string) string {
+
+	if parent == "" {
+		return field
+	}
+
+	return parent + "." + field
+}
+
+// SaveClusterSpec writes a cluster to a YAML or JSON file, chosen by the .json, .yaml or .yml extension.
+// The fields set by CCP, such as UUID, State and Nodes, are removed, and each sensitive value, such as
+// SSHPassword, is replaced with a ${NAME} reference to an environment variable named after the field,
+// e.g. ${SSH_PASSWORD}, so the file can be loaded again with LoadClusterSpec
+func SaveClusterSpec(path string, cluster *Cluster) error {
+
+	data, err := MarshalClusterSpec(cluster, specFormat(path))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// MarshalClusterSpec encodes a cluster as SaveClusterSpec does, in the SpecYAML or SpecJSON format
+func MarshalClusterSpec(cluster *Cluster, format string) ([]byte, error) {
+
+	if cluster == nil {
+		return nil, errors.New("Cluster is missing")
+	}
+
+	spec, err := specDocument(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case SpecJSON:
+		j, err := json.MarshalIndent(spec, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(j, '\n'), nil
+	case SpecYAML:
+		return marshalYAML(spec)
+	}
+
+	return nil, fmt.Errorf("Unknown cluster spec format %s", format)
+}
+
+// specDocument returns a copy of the cluster without the fields set by CCP and with its secrets
+// replaced by references to environment variables
+func specDocument(cluster *Cluster) (*Cluster, error) {
+
+	spec, err := withoutServerFields(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	referenceSecrets(reflect.ValueOf(spec).Elem(), "")
+
+	return spec, nil
+}
+
+// referenceSecrets replaces every sensitive string in the struct with a ${NAME} reference. Secrets in
+// a list are named after their index, e.g. ${HELM_CHARTS_0_PASSWORD}
+func referenceSecrets(v reflect.Value, prefix string) {
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			continue
+		}
+
+		name := jsonName(field)
+
+		switch {
+		case field.Tag.Get("sensitive") == "true" && value.Elem().Kind() == reflect.String:
+			ref := "${" + strings.ToUpper(prefix+name) + "}"
+			value.Set(reflect.ValueOf(&ref))
+		case value.Elem().Kind() == reflect.Struct:
+			referenceSecrets(value.Elem(), prefix+name+"_")
+		case value.Elem().Kind() == reflect.Slice:
+			list := value.Elem()
+			for j := 0; j < list.Len(); j++ {
+				item := list.Index(j)
+				if item.Kind() == reflect.Ptr {
+					if item.IsNil() {
+						continue
+					}
+					item = item.Elem()
+				}
+				if item.Kind() == reflect.Struct {
+					referenceSecrets(item, fmt.Sprintf("%s%s_%d_", prefix, name, j))
+				}
+			}
+		}
+	}
+}
+
+// marshalYAML encodes v as block style YAML, keeping the field order of its JSON encoding
+func marshalYAML(v interface{}) ([]byte, error) {
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(j, &node); err != nil {
+		return nil, err
+	}
+
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the flow and quoting styles of a document parsed from JSON, leaving the encoder to
+// quote only the strings which need it
+func blockStyle(node *yaml.Node) {
+
+	node.Style = 0
+
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
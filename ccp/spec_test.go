@@ -0,0 +1,293 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestParseClusterSpecEnv(t *testing.T) {
+
+	t.Setenv("CLUSTER_NAME", "demo")
+	t.Setenv("WORKERS", "3")
+	t.Setenv("SSH_PASSWORD", secret)
+	t.Setenv("NUMERIC_PASSWORD", "12345678")
+	t.Setenv("NULL_PASSWORD", "null")
+	t.Setenv("TILDE_PASSWORD", "~")
+	t.Setenv("BOOL_PASSWORD", "true")
+
+	tests := []struct {
+		name string
+		file string
+		spec string
+		want Cluster
+	}{
+		{name: "string", file: "spec.yaml", spec: "name: ${CLUSTER_NAME}", want: Cluster{Name: String("demo")}},
+		{name: "number", file: "spec.yaml", spec: "workers: ${WORKERS}", want: Cluster{Workers: Int64(3)}},
+		{name: "quoted", file: "spec.yaml", spec: `ssh_password: "${SSH_PASSWORD}"`, want: Cluster{SSHPassword: String(secret)}},
+		{name: "plain string", file: "spec.yaml", spec: "ssh_password: ${SSH_PASSWORD}", want: Cluster{SSHPassword: String(secret)}},
+		{name: "number as a string", file: "spec.yaml", spec: "ssh_password: ${NUMERIC_PASSWORD}", want: Cluster{SSHPassword: String("12345678")}},
+		{name: "null as a string", file: "spec.yaml", spec: "ssh_password: ${NULL_PASSWORD}", want: Cluster{SSHPassword: String("null")}},
+		{name: "tilde as a string", file: "spec.yaml", spec: "ssh_password: ${TILDE_PASSWORD}", want: Cluster{SSHPassword: String("~")}},
+		{name: "bool as a string", file: "spec.yaml", spec: "ssh_password: ${BOOL_PASSWORD}", want: Cluster{SSHPassword: String("true")}},
+		{name: "number in a list of strings", file: "spec.yaml", spec: "ntp_servers:\n  - ${NUMERIC_PASSWORD}", want: Cluster{NTPServers: &[]string{"12345678"}}},
+		{name: "escaped", file: "spec.yaml", spec: "description: $${CLUSTER_NAME}", want: Cluster{Description: String("${CLUSTER_NAME}")}},
+		{name: "within text", file: "spec.yaml", spec: "description: ${CLUSTER_NAME} has ${WORKERS} workers", want: Cluster{Description: String("demo has 3 workers")}},
+		{name: "nested", file: "spec.yaml", spec: "worker_node_pool:\n  vcpus: ${WORKERS}", want: Cluster{WorkerNodePool: &WorkerNodePool{VCPUs: Int64(3)}}},
+		{name: "list", file: "spec.yaml", spec: "ntp_servers:\n  - ${CLUSTER_NAME}.example.com", want: Cluster{NTPServers: &[]string{"demo.example.com"}}},
+		{name: "json", file: "spec.json", spec: `{"name": "${CLUSTER_NAME}", "ssh_password": "${SSH_PASSWORD}"}`, want: Cluster{Name: String("demo"), SSHPassword: String(secret)}},
+	}
+
+	for _, test := range tests {
+		cluster, err := ParseClusterSpec(test.file, []byte(test.spec))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*cluster, test.want) {
+			got, _ := json.Marshal(cluster)
+			want, _ := json.Marshal(test.want)
+			t.Errorf("%s: parsed %s, want %s", test.name, got, want)
+		}
+	}
+}
+
+func TestParseClusterSpecErrors(t *testing.T) {
+
+	t.Setenv("WORKERS", "many")
+
+	tests := []struct {
+		name string
+		file string
+		spec string
+		want []string
+	}{
+		{
+			name: "unknown field",
+			file: "spec.yaml",
+			spec: "name: demo\nworkerz: 2",
+			want: []string{"spec.yaml:2:1: unknown field workerz in Cluster"},
+		},
+		{
+			name: "wrong types",
+			file: "spec.yaml",
+			spec: "name: demo\nworkers: two\nnetworks: net-1\nis_harbor_enabled: maybe\nname: 12",
+			want: []string{
+				`spec.yaml:2:10: workers must be a whole number, found "two"`,
+				"spec.yaml:3:11: networks must be a list",
+				`spec.yaml:4:20: is_harbor_enabled must be true or false, found "maybe"`,
+				"spec.yaml:5:7: name must be a string, quote the value to use it as text",
+			},
+		},
+		{
+			name: "nested",
+			file: "spec.yaml",
+			spec: "name: demo\nworker_node_pool:\n  vcpus: [2]\n  colour: red",
+			want: []string{
+				"spec.yaml:3:10: worker_node_pool.vcpus must be a whole number",
+				"spec.yaml:4:3: unknown field colour in WorkerNodePool",
+			},
+		},
+		{
+			name: "list item",
+			file: "spec.yaml",
+			spec: "labels:\n  - key: env\n    value: 1",
+			want: []string{"spec.yaml:3:12: labels[0].value must be a string"},
+		},
+		{
+			name: "not a mapping",
+			file: "spec.yaml",
+			spec: "- demo",
+			want: []string{"spec.yaml:1:1: the cluster must be a mapping"},
+		},
+		{
+			name: "unset variable",
+			file: "spec.yaml",
+			spec: "name: demo\nssh_password: ${MISSING_SSH_PASSWORD}",
+			want: []string{"spec.yaml:2:15: environment variable MISSING_SSH_PASSWORD is not set"},
+		},
+		{
+			name: "variable of the wrong type",
+			file: "spec.yaml",
+			spec: "name: demo\nworkers: ${WORKERS}",
+			want: []string{`spec.yaml:2:10: workers must be a whole number, found "many"`},
+		},
+		{
+			name: "json",
+			file: "spec.json",
+			spec: "{\n  \"name\": \"demo\",\n  \"workers\": \"2\"\n}",
+			want: []string{`spec.json:3:14: workers must be a whole number, found "2"`},
+		},
+		{
+			name: "json syntax",
+			file: "spec.json",
+			spec: "{\n  \"name\": \"demo\",\n}",
+			want: []string{"spec.json:3:1: "},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseClusterSpec(test.file, []byte(test.spec))
+
+		errs, ok := err.(SpecErrors)
+		if !ok {
+			t.Errorf("%s: ParseClusterSpec returned %v, want SpecErrors", test.name, err)
+			continue
+		}
+		if len(errs) != len(test.want) {
+			t.Errorf("%s: got %d errors, want %d:\n%v", test.name, len(errs), len(test.want), errs)
+			continue
+		}
+		for i, want := range test.want {
+			if !strings.HasPrefix(errs[i].Error(), want) {
+				t.Errorf("%s: error %d is %q, want %q", test.name, i, errs[i].Error(), want)
+			}
+		}
+	}
+}
+
+type specAccount struct {
+	Username *string `json:"username"`
+	Password *string `json:"password" sensitive:"true"`
+}
+
+type specSecrets struct {
+	Name     *string        `json:"name"`
+	Admin    *specAccount   `json:"admin"`
+	Accounts *[]specAccount `json:"accounts"`
+	Users    *[]*User       `json:"users"`
+}
+
+func TestReferenceSecrets(t *testing.T) {
+
+	spec := specSecrets{
+		Name:  String("demo"),
+		Admin: &specAccount{Username: String("admin"), Password: String(secret)},
+		Accounts: &[]specAccount{
+			{Username: String("jsmith"), Password: String(secret)},
+			{Username: String("jdoe")},
+		},
+		Users: &[]*User{nil, {Username: String("ci"), Password: String(secret), Token: String(secret)}},
+	}
+
+	referenceSecrets(reflect.ValueOf(&spec).Elem(), "")
+
+	want := specSecrets{
+		Name:  String("demo"),
+		Admin: &specAccount{Username: String("admin"), Password: String("${ADMIN_PASSWORD}")},
+		Accounts: &[]specAccount{
+			{Username: String("jsmith"), Password: String("${ACCOUNTS_0_PASSWORD}")},
+			{Username: String("jdoe")},
+		},
+		Users: &[]*User{nil, {Username: String("ci"), Password: String("${USERS_1_PASSWORD}"), Token: String("${USERS_1_TOKEN}")}},
+	}
+
+	if !reflect.DeepEqual(spec, want) {
+		got, _ := json.Marshal(spec)
+		wanted, _ := json.Marshal(want)
+		t.Errorf("Secrets referenced as %s, want %s", got, wanted)
+	}
+}
+
+func TestClusterSpecRoundTrip(t *testing.T) {
+
+	// Secrets which read as another type in YAML must still load as strings
+	for _, password := range []string{secret, "12345678", "null", "~", "true"} {
+		t.Setenv("SSH_PASSWORD", password)
+
+		cluster := &Cluster{
+			UUID:        String("1234"),
+			State:       String(StateReady),
+			Name:        String("demo"),
+			Workers:     Int64(2),
+			SSHPassword: String(password),
+			Labels:      &[]Label{{Key: String("env"), Value: String("dev")}},
+		}
+
+		for _, format := range []string{SpecYAML, SpecJSON} {
+			data, err := MarshalClusterSpec(cluster, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), password) || strings.Contains(string(data), "1234") {
+				t.Errorf("%s %q: spec holds a secret or a field set by CCP:\n%s", format, password, data)
+			}
+
+			loaded, err := ParseClusterSpec("spec."+format, data)
+			if err != nil {
+				t.Errorf("%s %q: %v", format, password, err)
+				continue
+			}
+
+			want := *cluster
+			want.UUID = nil
+			want.State = nil
+
+			if !reflect.DeepEqual(*loaded, want) {
+				got, _ := json.Marshal(loaded)
+				t.Errorf("%s %q: loaded %s", format, password, got)
+			}
+		}
+	}
+}
+
+type specKinds struct {
+	Count   *uint              `json:"count"`
+	Ratio   *float64           `json:"ratio"`
+	Tags    *map[string]string `json:"tags"`
+	Extra   interface{}        `json:"extra"`
+	Updates chan string        `json:"updates"`
+}
+
+func TestCheckSpecKinds(t *testing.T) {
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{spec: "count: 3\nratio: 0.5\ntags:\n  env: dev\nextra: [1, {a: b}]"},
+		{spec: "ratio: 2"},
+		{spec: "count: -1", want: `spec.yaml:1:8: count must be a positive whole number, found "-1"`},
+		{spec: "count: 1.5", want: `spec.yaml:1:8: count must be a positive whole number, found "1.5"`},
+		{spec: "ratio: half", want: `spec.yaml:1:8: ratio must be a number, found "half"`},
+		{spec: "tags: [env]", want: "spec.yaml:1:7: tags must be a mapping"},
+		{spec: "tags:\n  env: 1", want: "spec.yaml:2:8: tags.env must be a string"},
+		{spec: "updates: x", want: "spec.yaml:1:10: updates has the type chan string, which cannot be read from a cluster spec"},
+	}
+
+	for _, test := range tests {
+		var errs SpecErrors
+		var node yaml.Node
+
+		if err := yaml.Unmarshal([]byte(test.spec), &node); err != nil {
+			t.Fatal(err)
+		}
+
+		checkSpec("spec.yaml", node.Content[0], reflect.TypeOf(specKinds{}), "", &errs)
+
+		switch {
+		case test.want == "" && len(errs) > 0:
+			t.Errorf("%q: %v", test.spec, errs)
+		case test.want != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), test.want)):
+			t.Errorf("%q: errors are %v, want %q", test.spec, errs, test.want)
+		}
+	}
+}
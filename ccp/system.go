@@ -16,10 +16,10 @@ or implied.*/
 package ccp
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"net/url"
 )
 
 type LivenessHealth struct {
@@ -49,17 +49,26 @@ type PodStatusList struct {
 	LastTransitionTime *string `json:"LastTransitionTime,omitempty"`
 }
 
+// Login starts a cookie session for the credentials of client, or of s when client is nil. The
+// credentials are sent as a form post so they never appear in the request URL, and the password is
+// removed from any error returned. A rejected login is an *APIError, e.g. with StatusCode 401
 func (s *Client) Login(client *Client) error {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/system/login?username=" + client.Username + "&password=" + client.Password)
-
-	j, err := json.Marshal(client)
+	if client == nil {
+		client = s
+	}
 
+	creds, err := client.credentials()
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(j))
+	form := url.Values{
+		"username": {creds.Username},
+		"password": {creds.Password},
+	}
+
+	req, err := s.NewRequest("POST", endpoint("/system/login"), form)
 	if err != nil {
 		return err
 	}
@@ -67,17 +76,25 @@ func (s *Client) Login(client *Client) error {
 	_, err = s.doRequest(req)
 
 	if err != nil {
-		return err
+		err = scrubSecret(err, creds.Password)
+		// An *APIError is returned as is so that callers can check its StatusCode, e.g. for a 401
+		if _, ok := err.(*APIError); ok {
+			return err
+		}
+		return fmt.Errorf("Login as %s failed: %v", creds.Username, err)
 	}
 
 	return nil
 }
 
 func (s *Client) GetLivenessHealth() (*LivenessHealth, error) {
+	return s.GetLivenessHealthContext(context.Background())
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/system/livenessHealth")
+// GetLivenessHealthContext is GetLivenessHealth with a context for cancellation
+func (s *Client) GetLivenessHealthContext(ctx context.Context) (*LivenessHealth, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/system/livenessHealth"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -98,10 +115,13 @@ func (s *Client) GetLivenessHealth() (*LivenessHealth, error) {
 }
 
 func (s *Client) GetHealth() (*Health, error) {
+	return s.GetHealthContext(context.Background())
+}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/system/health")
+// GetHealthContext is GetHealth with a context for cancellation
+func (s *Client) GetHealthContext(ctx context.Context) (*Health, error) {
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "GET", endpoint("/system/health"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,61 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoginReturnsAPIError(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		// Echo the password back, as some proxies do in their error pages
+		http.Error(w, "Invalid password "+r.PostForm.Get("password"), http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := NewClient("admin", "s3cret", srv.URL).Login(nil)
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Login returned %T %v, want an *APIError", err, err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want 401", apiErr.StatusCode)
+	}
+	if strings.Contains(apiErr.Error(), "s3cret") {
+		t.Errorf("Error %q contains the password", apiErr.Error())
+	}
+}
+
+func TestLoginWrapsOtherErrors(t *testing.T) {
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	err := NewClient("admin", "s3cret", srv.URL).Login(nil)
+
+	if err == nil || !strings.Contains(err.Error(), "Login as admin failed") {
+		t.Errorf("Login returned %v, want a login failure", err)
+	}
+	if _, ok := err.(*APIError); ok {
+		t.Errorf("Login returned an *APIError for a connection failure")
+	}
+}
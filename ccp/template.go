@@ -0,0 +1,195 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// serverFields are the top level cluster fields set by CCP. They cannot be changed, are left out of
+// saved specs and templates, and are rejected by AddCluster
+var serverFields = map[string]bool{
+	"uuid":                  true,
+	"state":                 true,
+	"nodes":                 true,
+	"cluster_env_url":       true,
+	"cluster_dashboard_url": true,
+	"ccp_private_ssh_key":   true,
+	"ccp_public_ssh_key":    true,
+	"ingress_vip_addr_id":   true,
+	"ingress_vips":          true,
+	"keepalived_vrid":       true,
+	"master_vip_addr_id":    true,
+	"master_vip":            true,
+	"master_mac_addresses":  true,
+}
+
+// TemplateOverrides replace fields of a cluster template. Fields left nil keep the exported value
+type TemplateOverrides struct {
+	Name        *string
+	Description *string
+	// ProviderClientConfigUUID also replaces the vSphere client config of the deployer, and is needed
+	// when the template is used with a different CCP
+	ProviderClientConfigUUID *string
+	// Networks also replaces the networks of the infra settings
+	Networks *[]string
+
+	Workers      *int64
+	Masters      *int64
+	WorkerVCPUs  *int64
+	WorkerMemory *int64
+	MasterVCPUs  *int64
+	MasterMemory *int64
+}
+
+// ExportClusterTemplate returns a cluster as a template for AddCluster, holding only the fields which
+// can be set when a cluster is created, with any overrides applied. See ClusterTemplate
+func (s *Client) ExportClusterTemplate(ctx context.Context, clusterName string, overrides *TemplateOverrides) (*Cluster, error) {
+
+	if clusterName == "" {
+		return nil, errors.New("Cluster name to export is required")
+	}
+
+	cluster, err := s.GetClusterContext(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return ClusterTemplate(cluster, overrides)
+}
+
+// ClusterTemplate returns a copy of a cluster without the fields set by CCP, such as UUID, State, Nodes,
+// MasterMACAddresses, CCPPrivateSSHKey and the dashboard and env URLs. The vSphere client config
+// expanded into the deployer, the identifiers of helm charts and an empty cluster level template are
+// removed too, so the result can be given to AddCluster against the same or a different CCP
+func ClusterTemplate(cluster *Cluster, overrides *TemplateOverrides) (*Cluster, error) {
+
+	if cluster == nil {
+		return nil, errors.New("Cluster is missing")
+	}
+
+	template, err := withoutServerFields(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.Deployer != nil && template.Deployer.Provider != nil {
+		template.Deployer.Provider.ClientConfig = nil
+	}
+
+	if template.HelmCharts != nil {
+		for i := range *template.HelmCharts {
+			(*template.HelmCharts)[i].HelmChartUUID = nil
+			(*template.HelmCharts)[i].ClusterUUID = nil
+		}
+	}
+
+	if overrides != nil {
+		overrides.apply(template)
+	}
+
+	// CCP rejects a cluster level template alongside node pool templates, including node pools added by
+	// the overrides
+	if template.Template != nil && (*template.Template == "" || (template.WorkerNodePool != nil && template.MasterNodePool != nil)) {
+		template.Template = nil
+	}
+
+	return template, nil
+}
+
+func (o *TemplateOverrides) apply(cluster *Cluster) {
+
+	if o.Name != nil {
+		cluster.Name = String(*o.Name)
+	}
+	if o.Description != nil {
+		cluster.Description = String(*o.Description)
+	}
+
+	if o.ProviderClientConfigUUID != nil {
+		cluster.ProviderClientConfigUUID = String(*o.ProviderClientConfigUUID)
+		if cluster.Deployer != nil && cluster.Deployer.Provider != nil {
+			cluster.Deployer.Provider.VsphereClientConfigUUID = String(*o.ProviderClientConfigUUID)
+		}
+	}
+
+	if o.Networks != nil {
+		networks := append([]string{}, *o.Networks...)
+		cluster.Networks = &networks
+		if cluster.Infra != nil {
+			cluster.Infra.Networks = &networks
+		}
+	}
+
+	if o.Workers != nil {
+		cluster.Workers = Int64(*o.Workers)
+	}
+	if o.Masters != nil {
+		cluster.Masters = Int64(*o.Masters)
+	}
+
+	if o.WorkerVCPUs != nil || o.WorkerMemory != nil {
+		if cluster.WorkerNodePool == nil {
+			cluster.WorkerNodePool = &WorkerNodePool{}
+		}
+		if o.WorkerVCPUs != nil {
+			cluster.WorkerNodePool.VCPUs = Int64(*o.WorkerVCPUs)
+		}
+		if o.WorkerMemory != nil {
+			cluster.WorkerNodePool.Memory = Int64(*o.WorkerMemory)
+		}
+	}
+
+	if o.MasterVCPUs != nil || o.MasterMemory != nil {
+		if cluster.MasterNodePool == nil {
+			cluster.MasterNodePool = &MasterNodePool{}
+		}
+		if o.MasterVCPUs != nil {
+			cluster.MasterNodePool.VCPUs = Int64(*o.MasterVCPUs)
+		}
+		if o.MasterMemory != nil {
+			cluster.MasterNodePool.Memory = Int64(*o.MasterMemory)
+		}
+	}
+}
+
+// withoutServerFields returns a deep copy of the cluster without the fields set by CCP
+func withoutServerFields(cluster *Cluster) (*Cluster, error) {
+
+	doc, err := clusterDocument(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	for field := range serverFields {
+		delete(doc, field)
+	}
+
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied Cluster
+
+	if err := json.Unmarshal(j, &copied); err != nil {
+		return nil, err
+	}
+
+	return &copied, nil
+}
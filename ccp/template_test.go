@@ -0,0 +1,256 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runningCluster is a cluster as returned by GetClusters, with the fields set by CCP
+func runningCluster() *Cluster {
+
+	return &Cluster{
+		UUID:                     String("1234"),
+		Name:                     String("demo"),
+		State:                    String(StateReady),
+		ProviderClientConfigUUID: String("vsphere-1"),
+		Networks:                 &[]string{"net-1"},
+		Workers:                  Int64(2),
+		Masters:                  Int64(1),
+		Template:                 String(""),
+		Nodes:                    &[]Node{{Name: String("demo-master-0"), State: String(StateReady)}},
+		ClusterEnvURL:            String("/2/clusters/1234/env"),
+		ClusterDashboardURL:      String("/2/clusters/1234/dashboard"),
+		CCPPrivateSSHKey:         String(secret),
+		CCPPublicSSHKey:          String("ssh-rsa AAAA"),
+		MasterVIP:                String("10.0.0.10"),
+		MasterMACAddresses:       &[]string{"00:50:56:00:00:01"},
+		IngressVIPs:              &[]string{"10.0.0.20"},
+		KeepalivedVRID:           Int64(7),
+		Deployer: &Deployer{
+			ProviderType: String("vsphere"),
+			Provider: &Provider{
+				VsphereClientConfigUUID: String("vsphere-1"),
+				ClientConfig:            &VsphereClientConfig{IP: String("10.0.0.1"), Username: String("admin")},
+			},
+		},
+		HelmCharts:     &[]HelmChart{{HelmChartUUID: String("chart-1"), ClusterUUID: String("1234"), Name: String("nginx")}},
+		Infra:          &Infra{Networks: &[]string{"net-1"}, Datacenter: String("dc-1")},
+		WorkerNodePool: &WorkerNodePool{VCPUs: Int64(2), Memory: Int64(16384), Template: String("ccp-tenant-image")},
+	}
+}
+
+// clusterTemplate is runningCluster as returned by ClusterTemplate without overrides
+func clusterTemplate() *Cluster {
+
+	return &Cluster{
+		Name:                     String("demo"),
+		ProviderClientConfigUUID: String("vsphere-1"),
+		Networks:                 &[]string{"net-1"},
+		Workers:                  Int64(2),
+		Masters:                  Int64(1),
+		Deployer: &Deployer{
+			ProviderType: String("vsphere"),
+			Provider:     &Provider{VsphereClientConfigUUID: String("vsphere-1")},
+		},
+		HelmCharts:     &[]HelmChart{{Name: String("nginx")}},
+		Infra:          &Infra{Networks: &[]string{"net-1"}, Datacenter: String("dc-1")},
+		WorkerNodePool: &WorkerNodePool{VCPUs: Int64(2), Memory: Int64(16384), Template: String("ccp-tenant-image")},
+	}
+}
+
+func TestClusterTemplate(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		cluster   func() *Cluster
+		overrides *TemplateOverrides
+		want      func(template *Cluster)
+	}{
+		{
+			name:    "server fields removed",
+			cluster: runningCluster,
+			want:    func(template *Cluster) {},
+		},
+		{
+			name: "template kept",
+			cluster: func() *Cluster {
+				cluster := runningCluster()
+				cluster.Template = String("ccp-tenant-image")
+				return cluster
+			},
+			want: func(template *Cluster) {
+				template.Template = String("ccp-tenant-image")
+			},
+		},
+		{
+			name: "template removed alongside node pool templates",
+			cluster: func() *Cluster {
+				cluster := runningCluster()
+				cluster.Template = String("ccp-tenant-image")
+				cluster.MasterNodePool = &MasterNodePool{Template: String("ccp-tenant-image")}
+				return cluster
+			},
+			want: func(template *Cluster) {
+				template.MasterNodePool = &MasterNodePool{Template: String("ccp-tenant-image")}
+			},
+		},
+		{
+			name: "template removed alongside a node pool added by the overrides",
+			cluster: func() *Cluster {
+				cluster := runningCluster()
+				cluster.Template = String("ccp-tenant-image")
+				return cluster
+			},
+			overrides: &TemplateOverrides{MasterMemory: Int64(8192)},
+			want: func(template *Cluster) {
+				template.MasterNodePool = &MasterNodePool{Memory: Int64(8192)}
+			},
+		},
+		{
+			name:    "name and description",
+			cluster: runningCluster,
+			overrides: &TemplateOverrides{
+				Name:        String("demo-copy"),
+				Description: String("Copy of demo"),
+			},
+			want: func(template *Cluster) {
+				template.Name = String("demo-copy")
+				template.Description = String("Copy of demo")
+			},
+		},
+		{
+			name:      "provider client config",
+			cluster:   runningCluster,
+			overrides: &TemplateOverrides{ProviderClientConfigUUID: String("vsphere-2")},
+			want: func(template *Cluster) {
+				template.ProviderClientConfigUUID = String("vsphere-2")
+				template.Deployer.Provider.VsphereClientConfigUUID = String("vsphere-2")
+			},
+		},
+		{
+			name:      "networks",
+			cluster:   runningCluster,
+			overrides: &TemplateOverrides{Networks: &[]string{"net-2", "net-3"}},
+			want: func(template *Cluster) {
+				template.Networks = &[]string{"net-2", "net-3"}
+				template.Infra.Networks = &[]string{"net-2", "net-3"}
+			},
+		},
+		{
+			name:    "sizing",
+			cluster: runningCluster,
+			overrides: &TemplateOverrides{
+				Workers:      Int64(5),
+				Masters:      Int64(3),
+				WorkerVCPUs:  Int64(8),
+				MasterMemory: Int64(8192),
+			},
+			want: func(template *Cluster) {
+				template.Workers = Int64(5)
+				template.Masters = Int64(3)
+				template.WorkerNodePool.VCPUs = Int64(8)
+				template.MasterNodePool = &MasterNodePool{Memory: Int64(8192)}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cluster := test.cluster()
+
+		template, err := ClusterTemplate(cluster, test.overrides)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		want := clusterTemplate()
+		test.want(want)
+
+		if !reflect.DeepEqual(template, want) {
+			got, _ := json.Marshal(template)
+			wanted, _ := json.Marshal(want)
+			t.Errorf("%s: template is\n%s\nwant\n%s", test.name, got, wanted)
+		}
+
+		// The cluster is copied, not changed
+		if !reflect.DeepEqual(cluster, test.cluster()) {
+			t.Errorf("%s: ClusterTemplate changed the cluster", test.name)
+		}
+	}
+}
+
+func TestClusterTemplateMissing(t *testing.T) {
+
+	if _, err := ClusterTemplate(nil, nil); err == nil {
+		t.Error("ClusterTemplate accepted a nil cluster")
+	}
+}
+
+func TestExportClusterTemplate(t *testing.T) {
+
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/2/clusters/demo" {
+			http.Error(w, "Cluster not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(runningCluster())
+	}))
+	defer srv.Close()
+
+	client := NewClient("admin", "secret", srv.URL)
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		// path is the only request sent, if any
+		path string
+	}{
+		{name: "demo", path: "/2/clusters/demo"},
+		{name: "missing", wantErr: true, path: "/2/clusters/missing"},
+		{name: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		paths = nil
+
+		template, err := client.ExportClusterTemplate(context.Background(), test.name, &TemplateOverrides{Name: String("demo-copy")})
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("ExportClusterTemplate(%q) succeeded, want an error", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("ExportClusterTemplate(%q): %v", test.name, err)
+		case !test.wantErr && (template.UUID != nil || *template.Name != "demo-copy"):
+			t.Errorf("ExportClusterTemplate(%q) returned UUID %v and name %s", test.name, template.UUID, *template.Name)
+		}
+
+		// The cluster is fetched by name rather than found in a list of every cluster
+		if strings.Join(paths, ", ") != test.path {
+			t.Errorf("ExportClusterTemplate(%q) sent %q, want %q", test.name, paths, test.path)
+		}
+	}
+}
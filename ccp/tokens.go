@@ -0,0 +1,55 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"net/http"
+)
+
+// Headers used to send Client.Token
+const (
+	AuthorizationHeader = "Authorization"
+	AuthTokenHeader     = "X-Auth-Token"
+)
+
+// NewClientWithToken creates a client which authenticates every request with the token rather than a
+// cookie session, so Login does not need to be called
+func NewClientWithToken(baseURL, token string) *Client {
+
+	return &Client{
+		BaseURL: baseURL,
+		Token:   token,
+	}
+}
+
+// authorize attaches the client token, if any, to the request
+func (s *Client) authorize(req *http.Request) {
+
+	if s.Token == "" {
+		return
+	}
+
+	header := s.TokenHeader
+	if header == "" {
+		header = AuthorizationHeader
+	}
+
+	if http.CanonicalHeaderKey(header) == AuthorizationHeader {
+		req.Header.Set(header, "Bearer "+s.Token)
+	} else {
+		req.Header.Set(header, s.Token)
+	}
+}
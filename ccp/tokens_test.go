@@ -0,0 +1,46 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTokenHeader(t *testing.T) {
+
+	tests := []struct {
+		header string
+		name   string
+		want   string
+	}{
+		{header: "", name: "Authorization", want: "Bearer abc"},
+		{header: "authorization", name: "Authorization", want: "Bearer abc"},
+		{header: "X-Auth-Token", name: "X-Auth-Token", want: "abc"},
+	}
+
+	for _, test := range tests {
+		client := NewClientWithToken("https://ccp.example.com", "abc")
+		client.TokenHeader = test.header
+
+		req, _ := http.NewRequest("GET", "https://ccp.example.com/2/clusters", nil)
+		client.authorize(req)
+
+		if got := req.Header.Get(test.name); got != test.want {
+			t.Errorf("TokenHeader %q: %s is %q, want %q", test.header, test.name, got, test.want)
+		}
+	}
+}
@@ -16,31 +16,27 @@ or implied.*/
 package ccp
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
 
 	validator "gopkg.in/validator.v2"
 )
 
 //UserAPIResponse
 type User struct {
-	Token     *string `json:"Token,omitempty"`
+	Token     *string `json:"Token,omitempty" sensitive:"true"`
 	Username  *string `json:"UserName,omitempty" validate:"nonzero"`
 	Disable   *bool   `json:"Disable,omitempty"`
 	Role      *string `json:"Role,omitempty" validate:"nonzero"`
 	FirstName *string `json:"FirstName,omitempty" `
 	LastName  *string `json:"LastName,omitempty"`
-	Password  *string `json:"Password,omitempty"`
+	Password  *string `json:"Password,omitempty" sensitive:"true"`
 }
 
 func (s *Client) GetUsers() ([]User, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/localusers")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/localusers"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +56,7 @@ func (s *Client) GetUsers() ([]User, error) {
 
 func (s *Client) GetUser(username string) (*User, error) {
 
-	url := fmt.Sprintf(s.BaseURL + "/2/localusers")
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.NewRequest("GET", endpoint("/localusers"), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +84,11 @@ func (s *Client) GetUser(username string) (*User, error) {
 }
 
 func (s *Client) AddUser(user *User) (*User, error) {
+	return s.AddUserContext(context.Background(), user)
+}
+
+// AddUserContext is AddUser with a context for cancellation
+func (s *Client) AddUserContext(ctx context.Context, user *User) (*User, error) {
 
 	var data User
 
@@ -97,20 +96,12 @@ func (s *Client) AddUser(user *User) (*User, error) {
 		return nil, errs
 	}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/localusers")
-
-	j, err := json.Marshal(user)
-
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(j))
+	req, err := s.NewRequestWithContext(ctx, "POST", endpoint("/localusers"), user)
 	if err != nil {
 		return nil, err
 	}
 
-	bytes, err := s.doRequest(req)
+	bytes, err := s.doMutation(req)
 
 	if err != nil {
 		return nil, err
@@ -128,6 +119,11 @@ func (s *Client) AddUser(user *User) (*User, error) {
 }
 
 func (s *Client) PatchUser(user *User) (*User, error) {
+	return s.PatchUserContext(context.Background(), user)
+}
+
+// PatchUserContext is PatchUser with a context for cancellation
+func (s *Client) PatchUserContext(ctx context.Context, user *User) (*User, error) {
 
 	var data User
 
@@ -137,21 +133,12 @@ func (s *Client) PatchUser(user *User) (*User, error) {
 
 	username := *user.Username
 
-	url := fmt.Sprintf(s.BaseURL + "/2/localusers/" + username)
-
-	j, err := json.Marshal(user)
-
-	if err != nil {
-
-		return nil, err
-	}
-
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(j))
+	req, err := s.NewRequestWithContext(ctx, "PATCH", endpoint("/localusers/{username}", username), user)
 	if err != nil {
 		return nil, err
 	}
 
-	bytes, err := s.doRequest(req)
+	bytes, err := s.doMutation(req)
 
 	if err != nil {
 		return nil, err
@@ -169,18 +156,21 @@ func (s *Client) PatchUser(user *User) (*User, error) {
 }
 
 func (s *Client) DeleteUser(username string) error {
+	return s.DeleteUserContext(context.Background(), username)
+}
+
+// DeleteUserContext is DeleteUser with a context for cancellation
+func (s *Client) DeleteUserContext(ctx context.Context, username string) error {
 
 	if username == "" {
 		return errors.New("Username of account to delete is required")
 	}
 
-	url := fmt.Sprintf(s.BaseURL + "/2/localusers/" + username)
-
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := s.NewRequestWithContext(ctx, "DELETE", endpoint("/localusers/{username}", username), nil)
 	if err != nil {
 		return err
 	}
-	_, err = s.doRequest(req)
+	_, err = s.doMutation(req)
 	if err != nil {
 		return err
 	}
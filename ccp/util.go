@@ -0,0 +1,57 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+// updateAttempts is how many times an update of a cluster is tried when the cluster changes underneath
+// it, see retryUpdate
+const updateAttempts = 3
+
+// retryUpdate calls update until it reports the update was made or fails, at most updateAttempts
+// times, and then returns conflict. update reads, changes and patches a value of a cluster, then reads
+// it back, returning false when the value read back is not the one written
+func retryUpdate(conflict error, update func() (bool, error)) error {
+
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		done, err := update()
+		if err != nil || done {
+			return err
+		}
+	}
+
+	return conflict
+}
+
+// containsString reports whether the list holds s
+func containsString(list []string, s string) bool {
+
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// derefString returns the string s points to, or an empty string when s is nil
+func derefString(s *string) string {
+
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,265 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Types of a ClusterEvent
+const (
+	// EventAdded is sent for each cluster found by the first list and for clusters created later
+	EventAdded = "ADDED"
+	// EventModified is sent when any field of a cluster changed since the previous list
+	EventModified = "MODIFIED"
+	// EventDeleted is sent when a cluster is gone, or no longer selected by the ListOptions of the watch
+	EventDeleted = "DELETED"
+	// EventSync is sent for every cluster on each resync, whether it changed or not
+	EventSync = "SYNC"
+	// EventError is sent when a list fails. The watch carries on with the next list
+	EventError = "ERROR"
+)
+
+// DefaultWatchInterval is how often clusters are listed when WatchOptions.Interval is not set
+const DefaultWatchInterval = 30 * time.Second
+
+// WatchOptions configure WatchClusters
+type WatchOptions struct {
+	// ListOptions select the clusters watched
+	ListOptions
+
+	// Interval is how often clusters are listed, DefaultWatchInterval when not set
+	Interval time.Duration
+
+	// ResyncPeriod, when set, is how often an EventSync is sent for every known cluster, so a consumer
+	// which missed or failed to handle an event catches up. It is rounded up to a whole number of
+	// intervals
+	ResyncPeriod time.Duration
+}
+
+// ClusterEvent is a change to a watched cluster
+type ClusterEvent struct {
+	Type string
+	// Old is the cluster before the change, nil for EventAdded
+	Old *Cluster
+	// New is the cluster after the change, nil for EventDeleted
+	New *Cluster
+	// Changes lists the changes of an EventModified to the state, nodes and Kubernetes version. A
+	// modification of any other field is sent without changes
+	Changes []ClusterChange
+	// Err is the error of an EventError
+	Err error
+}
+
+// ClusterChange is a change to a watched field of a cluster. Field is one of "state",
+// "kubernetes_version", "nodes", for the number of nodes, or "nodes.NAME.state"
+type ClusterChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// WatchClusters lists the clusters every interval and sends an event for each cluster added, modified
+// or deleted since the previous list. The first list sends an EventAdded for every cluster. Clusters are
+// matched by UUID. The channel is closed when ctx is done, or after an EventError when opts are invalid
+func (s *Client) WatchClusters(ctx context.Context, opts WatchOptions) <-chan ClusterEvent {
+
+	events := make(chan ClusterEvent)
+
+	go s.watchClusters(ctx, opts, events)
+
+	return events
+}
+
+func (s *Client) watchClusters(ctx context.Context, opts WatchOptions, events chan<- ClusterEvent) {
+
+	defer close(events)
+
+	send := func(event ClusterEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if _, err := newListFilter(opts.ListOptions, Cluster{}); err != nil {
+		send(ClusterEvent{Type: EventError, Err: err})
+		return
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := map[string]Cluster{}
+	lastSync := time.Now()
+
+	for {
+		clusters, err := s.ListClustersContext(ctx, opts.ListOptions)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			if !send(ClusterEvent{Type: EventError, Err: err}) {
+				return
+			}
+		default:
+			resync := opts.ResyncPeriod > 0 && time.Since(lastSync) >= opts.ResyncPeriod
+			if resync {
+				lastSync = time.Now()
+			}
+			for _, event := range diffClusterLists(known, clusters, resync) {
+				if !send(event) {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// diffClusterLists returns the events turning known into clusters, and updates known to match. The
+// clusters of the events are copies, so a receiver changing them does not change known
+func diffClusterLists(known map[string]Cluster, clusters []Cluster, resync bool) []ClusterEvent {
+
+	var events []ClusterEvent
+
+	seen := map[string]bool{}
+
+	for i := range clusters {
+		current := clusters[i]
+		uuid := derefString(current.UUID)
+		if uuid == "" || seen[uuid] {
+			continue
+		}
+		seen[uuid] = true
+
+		old, ok := known[uuid]
+		known[uuid] = current
+
+		switch {
+		case !ok:
+			events = append(events, ClusterEvent{Type: EventAdded, New: copyCluster(&current)})
+		case !reflect.DeepEqual(old, current):
+			events = append(events, ClusterEvent{Type: EventModified, Old: copyCluster(&old), New: copyCluster(&current), Changes: clusterChanges(&old, &current)})
+		case resync:
+			events = append(events, ClusterEvent{Type: EventSync, Old: copyCluster(&old), New: copyCluster(&current)})
+		}
+	}
+
+	var deleted []string
+	for uuid := range known {
+		if !seen[uuid] {
+			deleted = append(deleted, uuid)
+		}
+	}
+	sort.Strings(deleted)
+
+	for _, uuid := range deleted {
+		old := known[uuid]
+		delete(known, uuid)
+		events = append(events, ClusterEvent{Type: EventDeleted, Old: copyCluster(&old)})
+	}
+
+	return events
+}
+
+// copyCluster returns a deep copy of a cluster, sharing no pointers with it. Every field of Cluster
+// can be encoded as JSON, so the copy is made by encoding it
+func copyCluster(cluster *Cluster) *Cluster {
+
+	var copied Cluster
+
+	j, _ := json.Marshal(cluster)
+	json.Unmarshal(j, &copied)
+
+	return &copied
+}
+
+// clusterChanges returns the changes to the watched fields of a cluster
+func clusterChanges(old, current *Cluster) []ClusterChange {
+
+	var changes []ClusterChange
+
+	compare := func(field string, a, b interface{}) {
+		if a != b {
+			changes = append(changes, ClusterChange{Field: field, Old: a, New: b})
+		}
+	}
+
+	compare("state", derefString(old.State), derefString(current.State))
+	compare("kubernetes_version", derefString(old.KubernetesVersion), derefString(current.KubernetesVersion))
+
+	oldNodes, currentNodes := nodeStates(old), nodeStates(current)
+	compare("nodes", len(oldNodes), len(currentNodes))
+
+	names := map[string]bool{}
+	for name := range oldNodes {
+		names[name] = true
+	}
+	for name := range currentNodes {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		compare("nodes."+name+".state", oldNodes[name], currentNodes[name])
+	}
+
+	return changes
+}
+
+// nodeStates returns the state of each node of a cluster by name, or by UUID for nodes without a name.
+// A node which is added or removed shows as a change from or to an empty state
+func nodeStates(cluster *Cluster) map[string]string {
+
+	states := map[string]string{}
+
+	if cluster.Nodes == nil {
+		return states
+	}
+
+	for _, node := range *cluster.Nodes {
+		name := derefString(node.Name)
+		if name == "" {
+			name = derefString(node.UUID)
+		}
+		states[name] = derefString(node.State)
+	}
+
+	return states
+}
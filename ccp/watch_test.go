@@ -0,0 +1,367 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchCluster is a cluster with a node for each of nodeStates, named node-0, node-1 and so on
+func watchCluster(uuid, state, version string, nodeStates ...string) Cluster {
+
+	nodes := []Node{}
+	for i, nodeState := range nodeStates {
+		nodes = append(nodes, Node{Name: String(fmt.Sprintf("node-%d", i)), State: String(nodeState)})
+	}
+
+	return Cluster{
+		UUID:              String(uuid),
+		Name:              String("cluster-" + uuid),
+		State:             String(state),
+		KubernetesVersion: String(version),
+		Nodes:             &nodes,
+	}
+}
+
+// eventSummary is a ClusterEvent reduced to what the tests compare
+func eventSummary(event ClusterEvent) string {
+
+	uuid := ""
+	switch {
+	case event.New != nil:
+		uuid = *event.New.UUID
+	case event.Old != nil:
+		uuid = *event.Old.UUID
+	}
+
+	summary := event.Type + " " + uuid
+	for _, change := range event.Changes {
+		summary += fmt.Sprintf(" %s:%v->%v", change.Field, change.Old, change.New)
+	}
+
+	return summary
+}
+
+func TestDiffClusterLists(t *testing.T) {
+
+	initial := []Cluster{
+		watchCluster("a", StateReady, "1.10.1", StateReady, StateReady),
+		watchCluster("b", StateCreating, "1.10.1"),
+	}
+
+	tests := []struct {
+		name     string
+		clusters []Cluster
+		resync   bool
+		want     []string
+	}{
+		{name: "unchanged", clusters: initial, want: nil},
+		{
+			name:     "added",
+			clusters: append(initial[:2:2], watchCluster("c", StateCreating, "1.11.0")),
+			want:     []string{"ADDED c"},
+		},
+		{name: "removed", clusters: initial[:1], want: []string{"DELETED b"}},
+		{name: "all removed", clusters: nil, want: []string{"DELETED a", "DELETED b"}},
+		{
+			name:     "state changed",
+			clusters: []Cluster{initial[0], watchCluster("b", StateReady, "1.10.1")},
+			want:     []string{"MODIFIED b state:CREATING->READY"},
+		},
+		{
+			name:     "version changed",
+			clusters: []Cluster{watchCluster("a", StateReady, "1.11.0", StateReady, StateReady), initial[1]},
+			want:     []string{"MODIFIED a kubernetes_version:1.10.1->1.11.0"},
+		},
+		{
+			name:     "node state changed",
+			clusters: []Cluster{watchCluster("a", StateReady, "1.10.1", StateReady, StateError), initial[1]},
+			want:     []string{"MODIFIED a nodes.node-1.state:READY->ERROR"},
+		},
+		{
+			name:     "node added",
+			clusters: []Cluster{watchCluster("a", StateReady, "1.10.1", StateReady, StateReady, StateCreating), initial[1]},
+			want:     []string{"MODIFIED a nodes:2->3 nodes.node-2.state:->CREATING"},
+		},
+		{
+			name:     "node removed",
+			clusters: []Cluster{watchCluster("a", StateReady, "1.10.1", StateReady), initial[1]},
+			want:     []string{"MODIFIED a nodes:2->1 nodes.node-1.state:READY->"},
+		},
+		{
+			name: "other field changed",
+			clusters: func() []Cluster {
+				a := watchCluster("a", StateReady, "1.10.1", StateReady, StateReady)
+				a.Workers = Int64(3)
+				return []Cluster{a, initial[1]}
+			}(),
+			want: []string{"MODIFIED a"},
+		},
+		{
+			name:     "added, changed and removed",
+			clusters: []Cluster{watchCluster("c", StateReady, "1.11.0"), watchCluster("a", StateError, "1.10.1", StateReady, StateReady)},
+			want:     []string{"ADDED c", "MODIFIED a state:READY->ERROR", "DELETED b"},
+		},
+		{name: "resync", clusters: initial, resync: true, want: []string{"SYNC a", "SYNC b"}},
+		{
+			name:     "resync with changes",
+			clusters: []Cluster{initial[0], watchCluster("b", StateReady, "1.10.1")},
+			resync:   true,
+			want:     []string{"SYNC a", "MODIFIED b state:CREATING->READY"},
+		},
+		{
+			name:     "duplicates and clusters without a UUID skipped",
+			clusters: append(initial[:2:2], initial[0], Cluster{Name: String("pending")}),
+			want:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		known := map[string]Cluster{}
+		diffClusterLists(known, initial, false)
+
+		var got []string
+		for _, event := range diffClusterLists(known, test.clusters, test.resync) {
+			got = append(got, eventSummary(event))
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: events %q, want %q", test.name, got, test.want)
+		}
+
+		// known now matches the list, so diffing it again sends nothing
+		if events := diffClusterLists(known, test.clusters, false); len(events) != 0 {
+			t.Errorf("%s: second diff sent %d events, want none", test.name, len(events))
+		}
+	}
+}
+
+func TestDiffClusterListsCopies(t *testing.T) {
+
+	initial := []Cluster{watchCluster("a", StateReady, "1.10.1", StateReady)}
+
+	known := map[string]Cluster{}
+	events := diffClusterLists(known, initial, false)
+
+	// A receiver changing the cluster of an event, down to its nodes, does not change what is known
+	changed := events[0].New
+	*changed.State = StateError
+	(*changed.Nodes)[0].State = String(StateError)
+	*changed.Nodes = append(*changed.Nodes, Node{Name: String("node-1")})
+
+	if events := diffClusterLists(known, []Cluster{watchCluster("a", StateReady, "1.10.1", StateReady)}, true); len(events) != 1 || events[0].Type != EventSync {
+		t.Fatalf("Diff after the event was changed sent %v, want a single SYNC", events)
+	} else if events[0].Old == events[0].New || events[0].Old.Nodes == events[0].New.Nodes {
+		t.Error("Old and New share pointers")
+	}
+}
+
+// watchServer serves a list of clusters which the test changes between polls
+type watchServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	clusters []Cluster
+	fail     bool
+}
+
+func newWatchServer(clusters ...Cluster) *watchServer {
+
+	s := &watchServer{clusters: clusters}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.clusters)
+	}))
+
+	return s
+}
+
+func (s *watchServer) set(fail bool, clusters ...Cluster) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+	s.clusters = clusters
+}
+
+// nextEvents receives n events, failing the test if they do not arrive in time
+func nextEvents(t *testing.T, events <-chan ClusterEvent, n int) []string {
+
+	t.Helper()
+
+	var got []string
+	for len(got) < n {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Channel closed after %q, want %d events", got, n)
+			}
+			got = append(got, eventSummary(event))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Received %q, want %d events", got, n)
+		}
+	}
+
+	return got
+}
+
+// waitClosed fails the test unless events is closed in time, discarding any events still sent
+func waitClosed(t *testing.T, events <-chan ClusterEvent) {
+
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Channel not closed after the context was cancelled")
+		}
+	}
+}
+
+func TestWatchClusters(t *testing.T) {
+
+	initial := []Cluster{
+		watchCluster("a", StateReady, "1.10.1", StateReady),
+		watchCluster("b", StateCreating, "1.10.1"),
+	}
+
+	srv := newWatchServer(initial...)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := NewClient("admin", "secret", srv.URL).WatchClusters(ctx, WatchOptions{Interval: 10 * time.Millisecond})
+
+	steps := []struct {
+		name     string
+		fail     bool
+		clusters []Cluster
+		want     []string
+	}{
+		{
+			name:     "first list",
+			clusters: initial,
+			want:     []string{"ADDED a", "ADDED b"},
+		},
+		{
+			name: "state changed",
+			clusters: []Cluster{
+				watchCluster("a", StateReady, "1.10.1", StateReady),
+				watchCluster("b", StateReady, "1.10.1"),
+			},
+			want: []string{"MODIFIED b state:CREATING->READY"},
+		},
+		{
+			name: "added",
+			clusters: []Cluster{
+				watchCluster("a", StateReady, "1.10.1", StateReady),
+				watchCluster("b", StateReady, "1.10.1"),
+				watchCluster("c", StateCreating, "1.11.0"),
+			},
+			want: []string{"ADDED c"},
+		},
+		{name: "list failed", fail: true, want: []string{"ERROR "}},
+		{
+			name: "removed after the failure",
+			clusters: []Cluster{
+				watchCluster("b", StateReady, "1.10.1"),
+				watchCluster("c", StateCreating, "1.11.0"),
+			},
+			want: []string{"DELETED a"},
+		},
+	}
+
+	for _, step := range steps {
+		srv.set(step.fail, step.clusters...)
+
+		if got := nextEvents(t, events, len(step.want)); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: events %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	cancel()
+	waitClosed(t, events)
+}
+
+func TestWatchClustersCancelled(t *testing.T) {
+
+	srv := newWatchServer(
+		watchCluster("a", StateReady, "1.10.1"),
+		watchCluster("b", StateReady, "1.10.1"),
+	)
+	defer srv.Close()
+
+	client := NewClient("admin", "secret", srv.URL)
+
+	tests := []struct {
+		name string
+		// received is how many events are read before the context is cancelled
+		received int
+	}{
+		{name: "while waiting for the next list", received: 2},
+		{name: "while sending an event", received: 1},
+		{name: "before the first list", received: 0},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		events := client.WatchClusters(ctx, WatchOptions{Interval: time.Hour})
+		nextEvents(t, events, test.received)
+
+		cancel()
+		waitClosed(t, events)
+	}
+}
+
+func TestWatchClustersInvalidOptions(t *testing.T) {
+
+	events := NewClient("admin", "secret", "http://ccp.invalid").WatchClusters(context.Background(), WatchOptions{
+		ListOptions: ListOptions{LabelSelector: "env<prod"},
+	})
+
+	event, ok := <-events
+	if !ok || event.Type != EventError || event.Err == nil {
+		t.Fatalf("Received %+v, want an EventError", event)
+	}
+
+	if _, ok := <-events; ok {
+		t.Error("Channel not closed after an EventError for invalid options")
+	}
+}
@@ -0,0 +1,92 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Package ccpmock provides a mock implementation of the ccp service interfaces for unit tests.
+//
+//	mock := &ccpmock.Client{
+//		GetClustersFunc: func() ([]ccp.Cluster, error) {
+//			return []ccp.Cluster{{Name: ccp.String("test")}}, nil
+//		},
+//	}
+//
+//	var clusters ccp.ClusterService = mock
+package ccpmock
+
+//go:generate go run ../internal/mockgen -src ../ccp -out mock.go
+
+import (
+	"fmt"
+)
+
+// Call is a recorded call of a mock method
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// ErrNotStubbed is returned by mock methods whose function has not been set
+type ErrNotStubbed struct {
+	Method string
+}
+
+func (e *ErrNotStubbed) Error() string {
+	return fmt.Sprintf("ccpmock: %s is not stubbed, set %sFunc", e.Method, e.Method)
+}
+
+func notStubbed(method string) error {
+	return &ErrNotStubbed{Method: method}
+}
+
+func (m *Client) record(method string, args ...interface{}) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every call made so far, in order
+func (m *Client) Calls() []Call {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns the number of calls made to the named method
+func (m *Client) CallCount(method string) int {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, c := range m.calls {
+		if c.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Reset forgets all recorded calls
+func (m *Client) Reset() {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+}
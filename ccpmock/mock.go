@@ -0,0 +1,699 @@
+// Code generated by internal/mockgen from the ccp service interfaces. DO NOT EDIT.
+
+package ccpmock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+var _ ccp.API = (*Client)(nil)
+
+// Client is a mock of every ccp service interface. Each method records the call and then calls the
+// function in the field of the same name with a Func suffix. Methods whose function is not set return
+// zero values and an ErrNotStubbed error
+type Client struct {
+	// ccp.SystemService
+	LoginFunc                    func(client *ccp.Client) error
+	GetLivenessHealthFunc        func() (*ccp.LivenessHealth, error)
+	GetLivenessHealthContextFunc func(ctx context.Context) (*ccp.LivenessHealth, error)
+	GetHealthFunc                func() (*ccp.Health, error)
+	GetHealthContextFunc         func(ctx context.Context) (*ccp.Health, error)
+	CheckControlPlaneFunc        func(ctx context.Context) *ccp.ControlPlaneStatus
+
+	// ccp.UserService
+	GetUsersFunc          func() ([]ccp.User, error)
+	ListUsersFunc         func(opts ccp.ListOptions) ([]ccp.User, error)
+	ListUsersContextFunc  func(ctx context.Context, opts ccp.ListOptions) ([]ccp.User, error)
+	GetUserFunc           func(username string) (*ccp.User, error)
+	AddUserFunc           func(user *ccp.User) (*ccp.User, error)
+	AddUserContextFunc    func(ctx context.Context, user *ccp.User) (*ccp.User, error)
+	PatchUserFunc         func(user *ccp.User) (*ccp.User, error)
+	PatchUserContextFunc  func(ctx context.Context, user *ccp.User) (*ccp.User, error)
+	DeleteUserFunc        func(username string) error
+	DeleteUserContextFunc func(ctx context.Context, username string) error
+
+	// ccp.ClusterService
+	GetClustersFunc                func() ([]ccp.Cluster, error)
+	GetClustersContextFunc         func(ctx context.Context) ([]ccp.Cluster, error)
+	ListClustersFunc               func(opts ccp.ListOptions) ([]ccp.Cluster, error)
+	ListClustersContextFunc        func(ctx context.Context, opts ccp.ListOptions) ([]ccp.Cluster, error)
+	IterateClustersFunc            func(ctx context.Context, opts ccp.ListOptions) (*ccp.ClusterIterator, error)
+	WatchClustersFunc              func(ctx context.Context, opts ccp.WatchOptions) <-chan ccp.ClusterEvent
+	GetClusterFunc                 func(clusterName string) (*ccp.Cluster, error)
+	GetClusterContextFunc          func(ctx context.Context, clusterName string) (*ccp.Cluster, error)
+	GetClusterHealthFunc           func(clusterUUID string) (*ccp.ClusterHealth, error)
+	GetClusterHealthContextFunc    func(ctx context.Context, clusterUUID string) (*ccp.ClusterHealth, error)
+	GetClusterAuthzFunc            func(clusterUUID string) (*ccp.ClusterAuthz, error)
+	GetClusterAuthzContextFunc     func(ctx context.Context, clusterUUID string) (*ccp.ClusterAuthz, error)
+	SetClusterAuthzFunc            func(clusterUUID string, principals []string) (*ccp.ClusterAuthz, error)
+	SetClusterAuthzContextFunc     func(ctx context.Context, clusterUUID string, principals []string) (*ccp.ClusterAuthz, error)
+	GrantClusterAccessFunc         func(clusterUUID string, principal string) (*ccp.ClusterAuthz, error)
+	GrantClusterAccessContextFunc  func(ctx context.Context, clusterUUID string, principal string) (*ccp.ClusterAuthz, error)
+	RevokeClusterAccessFunc        func(clusterUUID string, principal string) (*ccp.ClusterAuthz, error)
+	RevokeClusterAccessContextFunc func(ctx context.Context, clusterUUID string, principal string) (*ccp.ClusterAuthz, error)
+	GetClusterDashboardFunc        func(clusterUUID string) (*string, error)
+	GetClusterEnvFunc              func(clusterUUID string) (*string, error)
+	GetClusterHelmChartsFunc       func(clusterUUID string) (*ccp.HelmChart, error)
+	AddClusterFunc                 func(cluster *ccp.Cluster) (*ccp.Cluster, error)
+	AddClusterContextFunc          func(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error)
+	AddClusterBasicFunc            func(cluster *ccp.Cluster) (*ccp.Cluster, error)
+	AddClusterBasicContextFunc     func(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error)
+	PatchClusterFunc               func(cluster *ccp.Cluster) (*ccp.Cluster, error)
+	PatchClusterContextFunc        func(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error)
+	DeleteClusterFunc              func(uuid string) error
+	DeleteClusterContextFunc       func(ctx context.Context, uuid string) error
+	PlanClusterFunc                func(ctx context.Context, desired *ccp.Cluster) (*ccp.ClusterPlan, error)
+	ApplyClusterFunc               func(ctx context.Context, desired *ccp.Cluster) (*ccp.ClusterPlan, error)
+	ExportClusterTemplateFunc      func(ctx context.Context, clusterName string, overrides *ccp.TemplateOverrides) (*ccp.Cluster, error)
+	SetClusterLabelsFunc           func(ctx context.Context, clusterName string, labels map[string]string) (*ccp.Cluster, error)
+	AddClusterLabelFunc            func(ctx context.Context, clusterName string, key string, value string) (*ccp.Cluster, error)
+	RemoveClusterLabelFunc         func(ctx context.Context, clusterName string, key string) (*ccp.Cluster, error)
+
+	// ccp.ProviderConfigService
+	GetProviderClientConfigsFunc                             func() ([]ccp.ProviderClientConfig, error)
+	ListProviderClientConfigsFunc                            func(opts ccp.ListOptions) ([]ccp.ProviderClientConfig, error)
+	ListProviderClientConfigsContextFunc                     func(ctx context.Context, opts ccp.ListOptions) ([]ccp.ProviderClientConfig, error)
+	GetProviderClientConfigFunc                              func(clientUUID string) (*ccp.ProviderClientConfig, error)
+	GetProviderClientConfigClustersFunc                      func(clientUUID string) ([]ccp.Cluster, error)
+	GetProviderClientConfigVsphereDatacenterFunc             func(clientUUID string) (*ccp.Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterClustersFunc     func(clientUUID string, datacenter string) (*ccp.Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterVMsFunc          func(clientUUID string, datacenter string) (*ccp.Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterNetworksFunc     func(clientUUID string, datacenter string) (*ccp.Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterDatastoresFunc   func(clientUUID string, datacenter string) (*ccp.Vsphere, error)
+	GetProviderClientConfigVsphereDatacenterClusterPoolsFunc func(clientUUID string, datacenter string, cluster string) (*ccp.Vsphere, error)
+
+	// ccp.ACIProfileService
+	GetACIProfilesFunc         func() ([]ccp.ACIProfile, error)
+	ListACIProfilesFunc        func(opts ccp.ListOptions) ([]ccp.ACIProfile, error)
+	ListACIProfilesContextFunc func(ctx context.Context, opts ccp.ListOptions) ([]ccp.ACIProfile, error)
+
+	// ccp.LDAPService
+	GetLDAPSetupFunc func() (*ccp.LDAPSetup, error)
+
+	// ccp.RBACService
+	GetRoleFunc func() (*ccp.Role, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (m *Client) Login(client *ccp.Client) error {
+	m.record("Login", client)
+	if m.LoginFunc == nil {
+		return notStubbed("Login")
+	}
+	return m.LoginFunc(client)
+}
+
+func (m *Client) GetLivenessHealth() (*ccp.LivenessHealth, error) {
+	m.record("GetLivenessHealth")
+	if m.GetLivenessHealthFunc == nil {
+		var r0 *ccp.LivenessHealth
+		return r0, notStubbed("GetLivenessHealth")
+	}
+	return m.GetLivenessHealthFunc()
+}
+
+func (m *Client) GetLivenessHealthContext(ctx context.Context) (*ccp.LivenessHealth, error) {
+	m.record("GetLivenessHealthContext", ctx)
+	if m.GetLivenessHealthContextFunc == nil {
+		var r0 *ccp.LivenessHealth
+		return r0, notStubbed("GetLivenessHealthContext")
+	}
+	return m.GetLivenessHealthContextFunc(ctx)
+}
+
+func (m *Client) GetHealth() (*ccp.Health, error) {
+	m.record("GetHealth")
+	if m.GetHealthFunc == nil {
+		var r0 *ccp.Health
+		return r0, notStubbed("GetHealth")
+	}
+	return m.GetHealthFunc()
+}
+
+func (m *Client) GetHealthContext(ctx context.Context) (*ccp.Health, error) {
+	m.record("GetHealthContext", ctx)
+	if m.GetHealthContextFunc == nil {
+		var r0 *ccp.Health
+		return r0, notStubbed("GetHealthContext")
+	}
+	return m.GetHealthContextFunc(ctx)
+}
+
+func (m *Client) CheckControlPlane(ctx context.Context) *ccp.ControlPlaneStatus {
+	m.record("CheckControlPlane", ctx)
+	if m.CheckControlPlaneFunc == nil {
+		var r0 *ccp.ControlPlaneStatus
+		return r0
+	}
+	return m.CheckControlPlaneFunc(ctx)
+}
+
+func (m *Client) GetUsers() ([]ccp.User, error) {
+	m.record("GetUsers")
+	if m.GetUsersFunc == nil {
+		var r0 []ccp.User
+		return r0, notStubbed("GetUsers")
+	}
+	return m.GetUsersFunc()
+}
+
+func (m *Client) ListUsers(opts ccp.ListOptions) ([]ccp.User, error) {
+	m.record("ListUsers", opts)
+	if m.ListUsersFunc == nil {
+		var r0 []ccp.User
+		return r0, notStubbed("ListUsers")
+	}
+	return m.ListUsersFunc(opts)
+}
+
+func (m *Client) ListUsersContext(ctx context.Context, opts ccp.ListOptions) ([]ccp.User, error) {
+	m.record("ListUsersContext", ctx, opts)
+	if m.ListUsersContextFunc == nil {
+		var r0 []ccp.User
+		return r0, notStubbed("ListUsersContext")
+	}
+	return m.ListUsersContextFunc(ctx, opts)
+}
+
+func (m *Client) GetUser(username string) (*ccp.User, error) {
+	m.record("GetUser", username)
+	if m.GetUserFunc == nil {
+		var r0 *ccp.User
+		return r0, notStubbed("GetUser")
+	}
+	return m.GetUserFunc(username)
+}
+
+func (m *Client) AddUser(user *ccp.User) (*ccp.User, error) {
+	m.record("AddUser", user)
+	if m.AddUserFunc == nil {
+		var r0 *ccp.User
+		return r0, notStubbed("AddUser")
+	}
+	return m.AddUserFunc(user)
+}
+
+func (m *Client) AddUserContext(ctx context.Context, user *ccp.User) (*ccp.User, error) {
+	m.record("AddUserContext", ctx, user)
+	if m.AddUserContextFunc == nil {
+		var r0 *ccp.User
+		return r0, notStubbed("AddUserContext")
+	}
+	return m.AddUserContextFunc(ctx, user)
+}
+
+func (m *Client) PatchUser(user *ccp.User) (*ccp.User, error) {
+	m.record("PatchUser", user)
+	if m.PatchUserFunc == nil {
+		var r0 *ccp.User
+		return r0, notStubbed("PatchUser")
+	}
+	return m.PatchUserFunc(user)
+}
+
+func (m *Client) PatchUserContext(ctx context.Context, user *ccp.User) (*ccp.User, error) {
+	m.record("PatchUserContext", ctx, user)
+	if m.PatchUserContextFunc == nil {
+		var r0 *ccp.User
+		return r0, notStubbed("PatchUserContext")
+	}
+	return m.PatchUserContextFunc(ctx, user)
+}
+
+func (m *Client) DeleteUser(username string) error {
+	m.record("DeleteUser", username)
+	if m.DeleteUserFunc == nil {
+		return notStubbed("DeleteUser")
+	}
+	return m.DeleteUserFunc(username)
+}
+
+func (m *Client) DeleteUserContext(ctx context.Context, username string) error {
+	m.record("DeleteUserContext", ctx, username)
+	if m.DeleteUserContextFunc == nil {
+		return notStubbed("DeleteUserContext")
+	}
+	return m.DeleteUserContextFunc(ctx, username)
+}
+
+func (m *Client) GetClusters() ([]ccp.Cluster, error) {
+	m.record("GetClusters")
+	if m.GetClustersFunc == nil {
+		var r0 []ccp.Cluster
+		return r0, notStubbed("GetClusters")
+	}
+	return m.GetClustersFunc()
+}
+
+func (m *Client) GetClustersContext(ctx context.Context) ([]ccp.Cluster, error) {
+	m.record("GetClustersContext", ctx)
+	if m.GetClustersContextFunc == nil {
+		var r0 []ccp.Cluster
+		return r0, notStubbed("GetClustersContext")
+	}
+	return m.GetClustersContextFunc(ctx)
+}
+
+func (m *Client) ListClusters(opts ccp.ListOptions) ([]ccp.Cluster, error) {
+	m.record("ListClusters", opts)
+	if m.ListClustersFunc == nil {
+		var r0 []ccp.Cluster
+		return r0, notStubbed("ListClusters")
+	}
+	return m.ListClustersFunc(opts)
+}
+
+func (m *Client) ListClustersContext(ctx context.Context, opts ccp.ListOptions) ([]ccp.Cluster, error) {
+	m.record("ListClustersContext", ctx, opts)
+	if m.ListClustersContextFunc == nil {
+		var r0 []ccp.Cluster
+		return r0, notStubbed("ListClustersContext")
+	}
+	return m.ListClustersContextFunc(ctx, opts)
+}
+
+func (m *Client) IterateClusters(ctx context.Context, opts ccp.ListOptions) (*ccp.ClusterIterator, error) {
+	m.record("IterateClusters", ctx, opts)
+	if m.IterateClustersFunc == nil {
+		var r0 *ccp.ClusterIterator
+		return r0, notStubbed("IterateClusters")
+	}
+	return m.IterateClustersFunc(ctx, opts)
+}
+
+func (m *Client) WatchClusters(ctx context.Context, opts ccp.WatchOptions) <-chan ccp.ClusterEvent {
+	m.record("WatchClusters", ctx, opts)
+	if m.WatchClustersFunc == nil {
+		var r0 <-chan ccp.ClusterEvent
+		return r0
+	}
+	return m.WatchClustersFunc(ctx, opts)
+}
+
+func (m *Client) GetCluster(clusterName string) (*ccp.Cluster, error) {
+	m.record("GetCluster", clusterName)
+	if m.GetClusterFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("GetCluster")
+	}
+	return m.GetClusterFunc(clusterName)
+}
+
+func (m *Client) GetClusterContext(ctx context.Context, clusterName string) (*ccp.Cluster, error) {
+	m.record("GetClusterContext", ctx, clusterName)
+	if m.GetClusterContextFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("GetClusterContext")
+	}
+	return m.GetClusterContextFunc(ctx, clusterName)
+}
+
+func (m *Client) GetClusterHealth(clusterUUID string) (*ccp.ClusterHealth, error) {
+	m.record("GetClusterHealth", clusterUUID)
+	if m.GetClusterHealthFunc == nil {
+		var r0 *ccp.ClusterHealth
+		return r0, notStubbed("GetClusterHealth")
+	}
+	return m.GetClusterHealthFunc(clusterUUID)
+}
+
+func (m *Client) GetClusterHealthContext(ctx context.Context, clusterUUID string) (*ccp.ClusterHealth, error) {
+	m.record("GetClusterHealthContext", ctx, clusterUUID)
+	if m.GetClusterHealthContextFunc == nil {
+		var r0 *ccp.ClusterHealth
+		return r0, notStubbed("GetClusterHealthContext")
+	}
+	return m.GetClusterHealthContextFunc(ctx, clusterUUID)
+}
+
+func (m *Client) GetClusterAuthz(clusterUUID string) (*ccp.ClusterAuthz, error) {
+	m.record("GetClusterAuthz", clusterUUID)
+	if m.GetClusterAuthzFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("GetClusterAuthz")
+	}
+	return m.GetClusterAuthzFunc(clusterUUID)
+}
+
+func (m *Client) GetClusterAuthzContext(ctx context.Context, clusterUUID string) (*ccp.ClusterAuthz, error) {
+	m.record("GetClusterAuthzContext", ctx, clusterUUID)
+	if m.GetClusterAuthzContextFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("GetClusterAuthzContext")
+	}
+	return m.GetClusterAuthzContextFunc(ctx, clusterUUID)
+}
+
+func (m *Client) SetClusterAuthz(clusterUUID string, principals []string) (*ccp.ClusterAuthz, error) {
+	m.record("SetClusterAuthz", clusterUUID, principals)
+	if m.SetClusterAuthzFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("SetClusterAuthz")
+	}
+	return m.SetClusterAuthzFunc(clusterUUID, principals)
+}
+
+func (m *Client) SetClusterAuthzContext(ctx context.Context, clusterUUID string, principals []string) (*ccp.ClusterAuthz, error) {
+	m.record("SetClusterAuthzContext", ctx, clusterUUID, principals)
+	if m.SetClusterAuthzContextFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("SetClusterAuthzContext")
+	}
+	return m.SetClusterAuthzContextFunc(ctx, clusterUUID, principals)
+}
+
+func (m *Client) GrantClusterAccess(clusterUUID string, principal string) (*ccp.ClusterAuthz, error) {
+	m.record("GrantClusterAccess", clusterUUID, principal)
+	if m.GrantClusterAccessFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("GrantClusterAccess")
+	}
+	return m.GrantClusterAccessFunc(clusterUUID, principal)
+}
+
+func (m *Client) GrantClusterAccessContext(ctx context.Context, clusterUUID string, principal string) (*ccp.ClusterAuthz, error) {
+	m.record("GrantClusterAccessContext", ctx, clusterUUID, principal)
+	if m.GrantClusterAccessContextFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("GrantClusterAccessContext")
+	}
+	return m.GrantClusterAccessContextFunc(ctx, clusterUUID, principal)
+}
+
+func (m *Client) RevokeClusterAccess(clusterUUID string, principal string) (*ccp.ClusterAuthz, error) {
+	m.record("RevokeClusterAccess", clusterUUID, principal)
+	if m.RevokeClusterAccessFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("RevokeClusterAccess")
+	}
+	return m.RevokeClusterAccessFunc(clusterUUID, principal)
+}
+
+func (m *Client) RevokeClusterAccessContext(ctx context.Context, clusterUUID string, principal string) (*ccp.ClusterAuthz, error) {
+	m.record("RevokeClusterAccessContext", ctx, clusterUUID, principal)
+	if m.RevokeClusterAccessContextFunc == nil {
+		var r0 *ccp.ClusterAuthz
+		return r0, notStubbed("RevokeClusterAccessContext")
+	}
+	return m.RevokeClusterAccessContextFunc(ctx, clusterUUID, principal)
+}
+
+func (m *Client) GetClusterDashboard(clusterUUID string) (*string, error) {
+	m.record("GetClusterDashboard", clusterUUID)
+	if m.GetClusterDashboardFunc == nil {
+		var r0 *string
+		return r0, notStubbed("GetClusterDashboard")
+	}
+	return m.GetClusterDashboardFunc(clusterUUID)
+}
+
+func (m *Client) GetClusterEnv(clusterUUID string) (*string, error) {
+	m.record("GetClusterEnv", clusterUUID)
+	if m.GetClusterEnvFunc == nil {
+		var r0 *string
+		return r0, notStubbed("GetClusterEnv")
+	}
+	return m.GetClusterEnvFunc(clusterUUID)
+}
+
+func (m *Client) GetClusterHelmCharts(clusterUUID string) (*ccp.HelmChart, error) {
+	m.record("GetClusterHelmCharts", clusterUUID)
+	if m.GetClusterHelmChartsFunc == nil {
+		var r0 *ccp.HelmChart
+		return r0, notStubbed("GetClusterHelmCharts")
+	}
+	return m.GetClusterHelmChartsFunc(clusterUUID)
+}
+
+func (m *Client) AddCluster(cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("AddCluster", cluster)
+	if m.AddClusterFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("AddCluster")
+	}
+	return m.AddClusterFunc(cluster)
+}
+
+func (m *Client) AddClusterContext(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("AddClusterContext", ctx, cluster)
+	if m.AddClusterContextFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("AddClusterContext")
+	}
+	return m.AddClusterContextFunc(ctx, cluster)
+}
+
+func (m *Client) AddClusterBasic(cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("AddClusterBasic", cluster)
+	if m.AddClusterBasicFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("AddClusterBasic")
+	}
+	return m.AddClusterBasicFunc(cluster)
+}
+
+func (m *Client) AddClusterBasicContext(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("AddClusterBasicContext", ctx, cluster)
+	if m.AddClusterBasicContextFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("AddClusterBasicContext")
+	}
+	return m.AddClusterBasicContextFunc(ctx, cluster)
+}
+
+func (m *Client) PatchCluster(cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("PatchCluster", cluster)
+	if m.PatchClusterFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("PatchCluster")
+	}
+	return m.PatchClusterFunc(cluster)
+}
+
+func (m *Client) PatchClusterContext(ctx context.Context, cluster *ccp.Cluster) (*ccp.Cluster, error) {
+	m.record("PatchClusterContext", ctx, cluster)
+	if m.PatchClusterContextFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("PatchClusterContext")
+	}
+	return m.PatchClusterContextFunc(ctx, cluster)
+}
+
+func (m *Client) DeleteCluster(uuid string) error {
+	m.record("DeleteCluster", uuid)
+	if m.DeleteClusterFunc == nil {
+		return notStubbed("DeleteCluster")
+	}
+	return m.DeleteClusterFunc(uuid)
+}
+
+func (m *Client) DeleteClusterContext(ctx context.Context, uuid string) error {
+	m.record("DeleteClusterContext", ctx, uuid)
+	if m.DeleteClusterContextFunc == nil {
+		return notStubbed("DeleteClusterContext")
+	}
+	return m.DeleteClusterContextFunc(ctx, uuid)
+}
+
+func (m *Client) PlanCluster(ctx context.Context, desired *ccp.Cluster) (*ccp.ClusterPlan, error) {
+	m.record("PlanCluster", ctx, desired)
+	if m.PlanClusterFunc == nil {
+		var r0 *ccp.ClusterPlan
+		return r0, notStubbed("PlanCluster")
+	}
+	return m.PlanClusterFunc(ctx, desired)
+}
+
+func (m *Client) ApplyCluster(ctx context.Context, desired *ccp.Cluster) (*ccp.ClusterPlan, error) {
+	m.record("ApplyCluster", ctx, desired)
+	if m.ApplyClusterFunc == nil {
+		var r0 *ccp.ClusterPlan
+		return r0, notStubbed("ApplyCluster")
+	}
+	return m.ApplyClusterFunc(ctx, desired)
+}
+
+func (m *Client) ExportClusterTemplate(ctx context.Context, clusterName string, overrides *ccp.TemplateOverrides) (*ccp.Cluster, error) {
+	m.record("ExportClusterTemplate", ctx, clusterName, overrides)
+	if m.ExportClusterTemplateFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("ExportClusterTemplate")
+	}
+	return m.ExportClusterTemplateFunc(ctx, clusterName, overrides)
+}
+
+func (m *Client) SetClusterLabels(ctx context.Context, clusterName string, labels map[string]string) (*ccp.Cluster, error) {
+	m.record("SetClusterLabels", ctx, clusterName, labels)
+	if m.SetClusterLabelsFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("SetClusterLabels")
+	}
+	return m.SetClusterLabelsFunc(ctx, clusterName, labels)
+}
+
+func (m *Client) AddClusterLabel(ctx context.Context, clusterName string, key string, value string) (*ccp.Cluster, error) {
+	m.record("AddClusterLabel", ctx, clusterName, key, value)
+	if m.AddClusterLabelFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("AddClusterLabel")
+	}
+	return m.AddClusterLabelFunc(ctx, clusterName, key, value)
+}
+
+func (m *Client) RemoveClusterLabel(ctx context.Context, clusterName string, key string) (*ccp.Cluster, error) {
+	m.record("RemoveClusterLabel", ctx, clusterName, key)
+	if m.RemoveClusterLabelFunc == nil {
+		var r0 *ccp.Cluster
+		return r0, notStubbed("RemoveClusterLabel")
+	}
+	return m.RemoveClusterLabelFunc(ctx, clusterName, key)
+}
+
+func (m *Client) GetProviderClientConfigs() ([]ccp.ProviderClientConfig, error) {
+	m.record("GetProviderClientConfigs")
+	if m.GetProviderClientConfigsFunc == nil {
+		var r0 []ccp.ProviderClientConfig
+		return r0, notStubbed("GetProviderClientConfigs")
+	}
+	return m.GetProviderClientConfigsFunc()
+}
+
+func (m *Client) ListProviderClientConfigs(opts ccp.ListOptions) ([]ccp.ProviderClientConfig, error) {
+	m.record("ListProviderClientConfigs", opts)
+	if m.ListProviderClientConfigsFunc == nil {
+		var r0 []ccp.ProviderClientConfig
+		return r0, notStubbed("ListProviderClientConfigs")
+	}
+	return m.ListProviderClientConfigsFunc(opts)
+}
+
+func (m *Client) ListProviderClientConfigsContext(ctx context.Context, opts ccp.ListOptions) ([]ccp.ProviderClientConfig, error) {
+	m.record("ListProviderClientConfigsContext", ctx, opts)
+	if m.ListProviderClientConfigsContextFunc == nil {
+		var r0 []ccp.ProviderClientConfig
+		return r0, notStubbed("ListProviderClientConfigsContext")
+	}
+	return m.ListProviderClientConfigsContextFunc(ctx, opts)
+}
+
+func (m *Client) GetProviderClientConfig(clientUUID string) (*ccp.ProviderClientConfig, error) {
+	m.record("GetProviderClientConfig", clientUUID)
+	if m.GetProviderClientConfigFunc == nil {
+		var r0 *ccp.ProviderClientConfig
+		return r0, notStubbed("GetProviderClientConfig")
+	}
+	return m.GetProviderClientConfigFunc(clientUUID)
+}
+
+func (m *Client) GetProviderClientConfigClusters(clientUUID string) ([]ccp.Cluster, error) {
+	m.record("GetProviderClientConfigClusters", clientUUID)
+	if m.GetProviderClientConfigClustersFunc == nil {
+		var r0 []ccp.Cluster
+		return r0, notStubbed("GetProviderClientConfigClusters")
+	}
+	return m.GetProviderClientConfigClustersFunc(clientUUID)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenter(clientUUID string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenter", clientUUID)
+	if m.GetProviderClientConfigVsphereDatacenterFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenter")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterFunc(clientUUID)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenterClusters(clientUUID string, datacenter string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenterClusters", clientUUID, datacenter)
+	if m.GetProviderClientConfigVsphereDatacenterClustersFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenterClusters")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterClustersFunc(clientUUID, datacenter)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenterVMs(clientUUID string, datacenter string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenterVMs", clientUUID, datacenter)
+	if m.GetProviderClientConfigVsphereDatacenterVMsFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenterVMs")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterVMsFunc(clientUUID, datacenter)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenterNetworks(clientUUID string, datacenter string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenterNetworks", clientUUID, datacenter)
+	if m.GetProviderClientConfigVsphereDatacenterNetworksFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenterNetworks")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterNetworksFunc(clientUUID, datacenter)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenterDatastores(clientUUID string, datacenter string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenterDatastores", clientUUID, datacenter)
+	if m.GetProviderClientConfigVsphereDatacenterDatastoresFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenterDatastores")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterDatastoresFunc(clientUUID, datacenter)
+}
+
+func (m *Client) GetProviderClientConfigVsphereDatacenterClusterPools(clientUUID string, datacenter string, cluster string) (*ccp.Vsphere, error) {
+	m.record("GetProviderClientConfigVsphereDatacenterClusterPools", clientUUID, datacenter, cluster)
+	if m.GetProviderClientConfigVsphereDatacenterClusterPoolsFunc == nil {
+		var r0 *ccp.Vsphere
+		return r0, notStubbed("GetProviderClientConfigVsphereDatacenterClusterPools")
+	}
+	return m.GetProviderClientConfigVsphereDatacenterClusterPoolsFunc(clientUUID, datacenter, cluster)
+}
+
+func (m *Client) GetACIProfiles() ([]ccp.ACIProfile, error) {
+	m.record("GetACIProfiles")
+	if m.GetACIProfilesFunc == nil {
+		var r0 []ccp.ACIProfile
+		return r0, notStubbed("GetACIProfiles")
+	}
+	return m.GetACIProfilesFunc()
+}
+
+func (m *Client) ListACIProfiles(opts ccp.ListOptions) ([]ccp.ACIProfile, error) {
+	m.record("ListACIProfiles", opts)
+	if m.ListACIProfilesFunc == nil {
+		var r0 []ccp.ACIProfile
+		return r0, notStubbed("ListACIProfiles")
+	}
+	return m.ListACIProfilesFunc(opts)
+}
+
+func (m *Client) ListACIProfilesContext(ctx context.Context, opts ccp.ListOptions) ([]ccp.ACIProfile, error) {
+	m.record("ListACIProfilesContext", ctx, opts)
+	if m.ListACIProfilesContextFunc == nil {
+		var r0 []ccp.ACIProfile
+		return r0, notStubbed("ListACIProfilesContext")
+	}
+	return m.ListACIProfilesContextFunc(ctx, opts)
+}
+
+func (m *Client) GetLDAPSetup() (*ccp.LDAPSetup, error) {
+	m.record("GetLDAPSetup")
+	if m.GetLDAPSetupFunc == nil {
+		var r0 *ccp.LDAPSetup
+		return r0, notStubbed("GetLDAPSetup")
+	}
+	return m.GetLDAPSetupFunc()
+}
+
+func (m *Client) GetRole() (*ccp.Role, error) {
+	m.record("GetRole")
+	if m.GetRoleFunc == nil {
+		var r0 *ccp.Role
+		return r0, notStubbed("GetRole")
+	}
+	return m.GetRoleFunc()
+}
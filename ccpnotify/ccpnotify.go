@@ -0,0 +1,345 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Package ccpnotify sends notifications of cluster lifecycle events, such as a cluster becoming ready
+// or a node failing, to sinks such as Slack, Microsoft Teams or any HTTP webhook
+package ccpnotify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// Kinds of Notification
+const (
+	// ClusterReady is sent when a cluster finishes provisioning
+	ClusterReady = "cluster_ready"
+	// ClusterFailed is sent when a cluster goes into the ERROR state
+	ClusterFailed = "cluster_failed"
+	// NodeFailed is sent for each node of a cluster which goes into the ERROR state
+	NodeFailed = "node_failed"
+	// ClusterDeleted is sent when a cluster is gone
+	ClusterDeleted = "cluster_deleted"
+)
+
+// Notification is a cluster lifecycle event. Its fields are the data of message templates, e.g.
+// {{.Name}} or {{.Node.ErrorLog}}
+type Notification struct {
+	Kind    string
+	Time    time.Time
+	Cluster ccp.Cluster
+	// Node is the failing node of a NodeFailed notification
+	Node *ccp.Node
+
+	// Name, UUID, State and DashboardURL are those of the cluster, and ErrorLog that of the failing
+	// node, or empty when not set
+	Name         string
+	UUID         string
+	State        string
+	DashboardURL string
+	ErrorLog     string
+
+	// Summary is a one line description, e.g. "Cluster demo is ready", and Details lists the state,
+	// dashboard URL and error log on separate lines
+	Summary string
+	Details string
+}
+
+// key identifies notifications which are duplicates of each other
+func (n *Notification) key() string {
+
+	node := ""
+	if n.Node != nil && n.Node.Name != nil {
+		node = *n.Node.Name
+	}
+
+	return strings.Join([]string{n.Kind, n.UUID, node, n.State}, "/")
+}
+
+// NewNotification creates a notification about a cluster and, for NodeFailed, one of its nodes
+func NewNotification(kind string, cluster *ccp.Cluster, node *ccp.Node) *Notification {
+
+	n := &Notification{
+		Kind:         kind,
+		Time:         time.Now(),
+		Cluster:      *cluster,
+		Node:         node,
+		Name:         str(cluster.Name),
+		UUID:         str(cluster.UUID),
+		State:        str(cluster.State),
+		DashboardURL: str(cluster.ClusterDashboardURL),
+	}
+
+	switch kind {
+	case ClusterReady:
+		n.Summary = fmt.Sprintf("Cluster %s is ready", n.Name)
+	case ClusterFailed:
+		n.Summary = fmt.Sprintf("Cluster %s has failed", n.Name)
+	case NodeFailed:
+		n.ErrorLog = str(node.ErrorLog)
+		n.Summary = fmt.Sprintf("Node %s of cluster %s has failed", str(node.Name), n.Name)
+	case ClusterDeleted:
+		n.Summary = fmt.Sprintf("Cluster %s has been deleted", n.Name)
+	default:
+		n.Summary = fmt.Sprintf("Cluster %s: %s", n.Name, kind)
+	}
+
+	details := []string{"State: " + n.State}
+	if n.DashboardURL != "" {
+		details = append(details, "Dashboard: "+n.DashboardURL)
+	}
+	if n.ErrorLog != "" {
+		details = append(details, "Error log:\n"+n.ErrorLog)
+	}
+	n.Details = strings.Join(details, "\n")
+
+	return n
+}
+
+// Notifications returns the notifications for a change to a cluster. A cluster becoming READY from any
+// other state is ready, and a cluster or node going into the ERROR state has failed. Clusters found by
+// the first list of a watch are not notified, whatever their state
+func Notifications(event ccp.ClusterEvent) []*Notification {
+
+	var notifications []*Notification
+
+	switch event.Type {
+	case ccp.EventModified:
+		for _, change := range event.Changes {
+			switch {
+			case change.Field == "state" && change.New == ccp.StateReady:
+				notifications = append(notifications, NewNotification(ClusterReady, event.New, nil))
+			case change.Field == "state" && change.New == ccp.StateError:
+				notifications = append(notifications, NewNotification(ClusterFailed, event.New, nil))
+			case strings.HasPrefix(change.Field, "nodes.") && change.New == ccp.StateError:
+				if node := findNode(event.New, strings.TrimSuffix(strings.TrimPrefix(change.Field, "nodes."), ".state")); node != nil {
+					notifications = append(notifications, NewNotification(NodeFailed, event.New, node))
+				}
+			}
+		}
+	case ccp.EventDeleted:
+		notifications = append(notifications, NewNotification(ClusterDeleted, event.Old, nil))
+	}
+
+	return notifications
+}
+
+func findNode(cluster *ccp.Cluster, name string) *ccp.Node {
+
+	if cluster.Nodes == nil {
+		return nil
+	}
+
+	for i, node := range *cluster.Nodes {
+		if str(node.Name) == name || (node.Name == nil && str(node.UUID) == name) {
+			return &(*cluster.Nodes)[i]
+		}
+	}
+
+	return nil
+}
+
+// Sink delivers notifications, e.g. to a webhook
+type Sink interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// PermanentError is returned by a sink for a notification which will never be delivered, such as one
+// rejected as invalid, so the Dispatcher does not retry it
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Defaults of a Dispatcher
+const (
+	DefaultRetries     = 3
+	DefaultBackoff     = time.Second
+	DefaultDedupWindow = time.Hour
+)
+
+// Dispatcher sends each notification to every sink, retrying failed deliveries and dropping duplicates.
+// The zero values of its fields select the defaults
+type Dispatcher struct {
+	Sinks []Sink
+
+	// Retries is how many times a failed delivery is retried, with a delay starting at Backoff and
+	// doubling after each retry. A negative value disables retries
+	Retries int
+	Backoff time.Duration
+
+	// DedupWindow is how long a notification of the same kind, cluster, node and state is dropped for
+	// after being sent, e.g. for a node failing again before it is fixed
+	DedupWindow time.Duration
+
+	// OnError, when set, is called for each delivery which failed after every retry
+	OnError func(sink Sink, n *Notification, err error)
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewDispatcher creates a dispatcher sending to the sinks
+func NewDispatcher(sinks ...Sink) *Dispatcher {
+	return &Dispatcher{Sinks: sinks}
+}
+
+// Run watches the clusters, usually of a *ccp.Client, and dispatches their notifications until ctx is
+// done. Lists which fail are retried on the next interval of the watch. An error is returned straight
+// away when opts are invalid
+func (d *Dispatcher) Run(ctx context.Context, clusters ccp.ClusterService, opts ccp.WatchOptions) error {
+
+	var last error
+
+	for event := range clusters.WatchClusters(ctx, opts) {
+		if event.Type == ccp.EventError {
+			last = event.Err
+			continue
+		}
+		for _, n := range Notifications(event) {
+			d.Dispatch(ctx, n)
+		}
+	}
+
+	// The watch only ends before ctx is done when opts are invalid
+	if ctx.Err() == nil {
+		return last
+	}
+
+	return ctx.Err()
+}
+
+// Dispatch sends the notification to every sink, unless a duplicate was sent within the dedup window.
+// It returns the first error of a sink which failed after every retry. A notification no sink accepted
+// is not recorded as sent, so a duplicate dispatched later is sent again
+func (d *Dispatcher) Dispatch(ctx context.Context, n *Notification) error {
+
+	if d.duplicate(n) {
+		return nil
+	}
+
+	var first error
+	delivered := false
+
+	for _, sink := range d.Sinks {
+		if err := d.deliver(ctx, sink, n); err != nil {
+			if d.OnError != nil {
+				d.OnError(sink, n, err)
+			}
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		delivered = true
+	}
+
+	if !delivered {
+		d.forget(n)
+	}
+
+	return first
+}
+
+// duplicate reports whether a duplicate of the notification was sent within the dedup window, and
+// records the notification as sent otherwise. It is recorded before delivery so a duplicate dispatched
+// at the same time is dropped
+func (d *Dispatcher) duplicate(n *Notification) bool {
+
+	window := d.DedupWindow
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.sent == nil {
+		d.sent = map[string]time.Time{}
+	}
+
+	now := time.Now()
+
+	for key, sent := range d.sent {
+		if now.Sub(sent) >= window {
+			delete(d.sent, key)
+		}
+	}
+
+	key := n.key()
+	if _, ok := d.sent[key]; ok {
+		return true
+	}
+	d.sent[key] = now
+
+	return false
+}
+
+// forget removes the record of the notification as sent
+func (d *Dispatcher) forget(n *Notification) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.sent, n.key())
+}
+
+// deliver sends the notification to a sink, retrying with backoff
+func (d *Dispatcher) deliver(ctx context.Context, sink Sink, n *Notification) error {
+
+	retries := d.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	}
+
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := sink.Notify(ctx, n)
+		if err == nil {
+			return nil
+		}
+		if _, permanent := err.(*PermanentError); permanent || attempt >= retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func str(s *string) string {
+
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
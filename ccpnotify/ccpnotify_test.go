@@ -0,0 +1,277 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccpnotify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	"github.com/conmurphy/ccp-clientlibrary-go/ccptest"
+)
+
+// receiver is a local webhook endpoint which fails the first failures requests it gets
+type receiver struct {
+	*httptest.Server
+	failures int
+
+	mu       sync.Mutex
+	requests int
+	payloads []webhookPayload
+}
+
+func newReceiver(failures int) *receiver {
+
+	r := &receiver{failures: failures}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests++
+		if r.requests <= r.failures {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.payloads = append(r.payloads, payload)
+	}))
+
+	return r
+}
+
+// wait returns the payloads received once there are at least n, failing the test after a timeout
+func (r *receiver) wait(t *testing.T, n int) []webhookPayload {
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		r.mu.Lock()
+		payloads := append([]webhookPayload(nil), r.payloads...)
+		r.mu.Unlock()
+
+		if len(payloads) >= n {
+			return payloads
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Received %d notifications, want %d: %+v", len(payloads), n, payloads)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDispatcherNotifiesClusterLifecycle(t *testing.T) {
+
+	srv := ccptest.NewServer()
+	defer srv.Close()
+	srv.ProvisionDelay = 50 * time.Millisecond
+
+	client := srv.NewClient()
+	if err := client.Login(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := newReceiver(1)
+	defer hook.Close()
+
+	webhook, err := NewWebhook(hook.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(webhook)
+	d.Backoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- d.Run(ctx, client, ccp.WatchOptions{Interval: 10 * time.Millisecond})
+	}()
+
+	// Let the first list of the watch complete before the cluster is added
+	time.Sleep(30 * time.Millisecond)
+
+	cluster := srv.AddCluster(ccp.Cluster{
+		Name:    ccp.String("demo"),
+		State:   ccp.String(ccp.StateCreating),
+		Workers: ccp.Int64(1),
+		Masters: ccp.Int64(1),
+	})
+
+	// The first delivery fails and is retried
+	payloads := hook.wait(t, 1)
+	if payloads[0].Kind != ClusterReady || payloads[0].Cluster != "demo" {
+		t.Fatalf("First notification is %+v, want demo ready", payloads[0])
+	}
+
+	if err := srv.SetClusterState(*cluster.UUID, ccp.StateError); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cluster and both of its nodes failed
+	payloads = hook.wait(t, 4)
+	kinds := map[string]int{}
+	for _, p := range payloads[1:] {
+		kinds[p.Kind]++
+	}
+	if kinds[ClusterFailed] != 1 || kinds[NodeFailed] != 2 {
+		t.Errorf("Notifications after the failure are %+v", payloads[1:])
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+}
+
+func TestDispatcherDropsDuplicates(t *testing.T) {
+
+	hook := newReceiver(0)
+	defer hook.Close()
+
+	webhook, err := NewWebhook(hook.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(webhook)
+	cluster := &ccp.Cluster{UUID: ccp.String("1234"), Name: ccp.String("demo"), State: ccp.String(ccp.StateError)}
+
+	for i := 0; i < 3; i++ {
+		if err := d.Dispatch(context.Background(), NewNotification(ClusterFailed, cluster, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if payloads := hook.wait(t, 1); len(payloads) != 1 {
+		t.Errorf("Received %d notifications, want 1", len(payloads))
+	}
+}
+
+func TestDispatcherRetriesUndelivered(t *testing.T) {
+
+	cluster := &ccp.Cluster{UUID: ccp.String("1234"), Name: ccp.String("demo"), State: ccp.String(ccp.StateError)}
+
+	tests := []struct {
+		name string
+		// failures is how many requests each receiver fails
+		failures []int
+		// dispatches is how many times the notification is dispatched, and received how many
+		// notifications each receiver gets
+		dispatches int
+		received   []int
+	}{
+		{name: "delivered", failures: []int{0}, dispatches: 2, received: []int{1}},
+		{name: "failed then delivered", failures: []int{1}, dispatches: 3, received: []int{1}},
+		{name: "failed twice then delivered", failures: []int{2}, dispatches: 3, received: []int{1}},
+		{name: "always failed", failures: []int{9}, dispatches: 3, received: []int{0}},
+		{name: "one of two sinks failed", failures: []int{0, 9}, dispatches: 2, received: []int{1, 0}},
+	}
+
+	for _, test := range tests {
+		d := NewDispatcher()
+		d.Retries = -1
+
+		var hooks []*receiver
+		for _, failures := range test.failures {
+			hook := newReceiver(failures)
+			defer hook.Close()
+
+			webhook, err := NewWebhook(hook.URL, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			hooks = append(hooks, hook)
+			d.Sinks = append(d.Sinks, webhook)
+		}
+
+		for i := 0; i < test.dispatches; i++ {
+			d.Dispatch(context.Background(), NewNotification(ClusterFailed, cluster, nil))
+		}
+
+		for i, hook := range hooks {
+			hook.mu.Lock()
+			received := len(hook.payloads)
+			hook.mu.Unlock()
+
+			if received != test.received[i] {
+				t.Errorf("%s: sink %d received %d notifications, want %d", test.name, i, received, test.received[i])
+			}
+		}
+	}
+}
+
+func TestWebhookDoesNotRetryRejectedNotifications(t *testing.T) {
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(SlackWebhook(srv.URL))
+	d.Backoff = time.Millisecond
+
+	err := d.Dispatch(context.Background(), NewNotification(ClusterReady, &ccp.Cluster{Name: ccp.String("demo")}, nil))
+
+	if _, ok := err.(*PermanentError); !ok || requests != 1 {
+		t.Errorf("Dispatch returned %v after %d requests, want a PermanentError after 1", err, requests)
+	}
+}
+
+func TestWebhookErrorsLeaveOutTheURL(t *testing.T) {
+
+	const token = "T000/B000/s3cret"
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "unreachable", url: closed.URL + "/services/" + token + "?token=" + token},
+		{name: "invalid", url: "https://hooks.example.com/services/" + token + "/\x7f%zz"},
+	}
+
+	for _, test := range tests {
+		err := SlackWebhook(test.url).Notify(context.Background(), NewNotification(ClusterReady, &ccp.Cluster{Name: ccp.String("demo")}, nil))
+
+		if err == nil {
+			t.Errorf("%s: Notify succeeded", test.name)
+			continue
+		}
+		if strings.Contains(err.Error(), token) {
+			t.Errorf("%s: error %q holds the webhook URL", test.name, err)
+		}
+	}
+}
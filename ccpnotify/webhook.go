@@ -0,0 +1,213 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccpnotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"text/template"
+)
+
+// Message templates of the webhooks created by SlackWebhook and TeamsWebhook. The json function
+// quotes a value as a JSON string
+const (
+	SlackTemplate = `{"text": {{json (printf "*%s*\n%s" .Summary .Details)}}}`
+	TeamsTemplate = `{"@type": "MessageCard", "@context": "http://schema.org/extensions", "summary": {{json .Summary}}, "title": {{json .Summary}}, "text": {{json .Details}}}`
+)
+
+// Webhook is a sink posting notifications to an HTTP endpoint
+type Webhook struct {
+	URL string
+	// Method defaults to POST
+	Method  string
+	Headers map[string]string
+
+	// Template renders the body of the request from the Notification. When nil the body is the
+	// notification as JSON, without the full cluster
+	Template    *template.Template
+	ContentType string
+
+	// Kinds, when set, are the kinds of notification sent to the webhook, e.g. NodeFailed
+	Kinds []string
+
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewWebhook creates a webhook sink rendering its body with the text/template. An empty template sends
+// the notification as JSON
+func NewWebhook(url, body string) (*Webhook, error) {
+
+	w := &Webhook{URL: url, ContentType: "application/json"}
+
+	if body != "" {
+		t, err := template.New("webhook").Funcs(templateFuncs).Parse(body)
+		if err != nil {
+			return nil, err
+		}
+		w.Template = t
+	}
+
+	return w, nil
+}
+
+// SlackWebhook creates a sink posting to a Slack incoming webhook URL
+func SlackWebhook(url string) *Webhook {
+
+	w, _ := NewWebhook(url, SlackTemplate)
+
+	return w
+}
+
+// TeamsWebhook creates a sink posting to a Microsoft Teams incoming webhook URL
+func TeamsWebhook(url string) *Webhook {
+
+	w, _ := NewWebhook(url, TeamsTemplate)
+
+	return w
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		j, err := json.Marshal(v)
+		return string(j), err
+	},
+}
+
+// webhookPayload is the body of a webhook without a template
+type webhookPayload struct {
+	Kind         string `json:"kind"`
+	Summary      string `json:"summary"`
+	Time         string `json:"time"`
+	Cluster      string `json:"cluster"`
+	UUID         string `json:"uuid"`
+	State        string `json:"state"`
+	Node         string `json:"node,omitempty"`
+	ErrorLog     string `json:"error_log,omitempty"`
+	DashboardURL string `json:"dashboard_url,omitempty"`
+}
+
+// Notify sends the notification, unless its kind is not one of Kinds. Responses with a 4xx status,
+// other than 408 and 429, are a PermanentError
+func (w *Webhook) Notify(ctx context.Context, n *Notification) error {
+
+	if len(w.Kinds) > 0 && !contains(w.Kinds, n.Kind) {
+		return nil
+	}
+
+	var body bytes.Buffer
+
+	if w.Template != nil {
+		if err := w.Template.Execute(&body, n); err != nil {
+			return &PermanentError{Err: err}
+		}
+	} else {
+		payload := webhookPayload{
+			Kind:         n.Kind,
+			Summary:      n.Summary,
+			Time:         n.Time.UTC().Format("2006-01-02T15:04:05Z"),
+			Cluster:      n.Name,
+			UUID:         n.UUID,
+			State:        n.State,
+			ErrorLog:     n.ErrorLog,
+			DashboardURL: n.DashboardURL,
+		}
+		if n.Node != nil {
+			payload.Node = str(n.Node.Name)
+		}
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return &PermanentError{Err: err}
+		}
+	}
+
+	method := w.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequest(method, w.URL, &body)
+	if err != nil {
+		return &PermanentError{Err: withoutURL(err)}
+	}
+	req = req.WithContext(ctx)
+
+	if w.ContentType != "" {
+		req.Header.Set("Content-Type", w.ContentType)
+	}
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := w.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	message, _ := ioutil.ReadAll(resp.Body)
+	// The URL is left out as webhook URLs often hold a secret
+	err = fmt.Errorf("Webhook responded %s: %s", resp.Status, bytes.TrimSpace(message))
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &PermanentError{Err: err}
+	}
+
+	return err
+}
+
+// withoutURL removes the path and query of the webhook URL from an error of the HTTP client, as webhook
+// URLs often hold a secret
+func withoutURL(err error) error {
+
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	clean := *urlErr
+	clean.URL = "webhook"
+
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil && u.Host != "" {
+		clean.URL = u.Scheme + "://" + u.Host
+	}
+
+	return &clean
+}
+
+func contains(list []string, s string) bool {
+
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Package ccpotel traces the calls made by a ccp.Client with OpenTelemetry
+package ccpotel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/conmurphy/ccp-clientlibrary-go/ccpotel"
+
+// Tracer implements ccp.Tracer, creating a client span for every CCP API call
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates a Tracer using the provider, or the global provider when nil
+func NewTracer(provider trace.TracerProvider) *Tracer {
+
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// StartRequest starts a span named after the method and endpoint template, e.g. "GET /2/clusters/{uuid}"
+func (t *Tracer) StartRequest(ctx context.Context, info ccp.RequestInfo) (context.Context, func(ccp.RequestResult)) {
+
+	ctx, span := t.tracer.Start(ctx, info.Method+" "+info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", info.Method),
+			attribute.String("http.route", info.Endpoint),
+			attribute.String("url.full", info.URL),
+		),
+	)
+
+	return ctx, func(result ccp.RequestResult) {
+
+		if result.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", result.StatusCode))
+		}
+		span.SetAttributes(attribute.Int("ccp.retries", result.Retries))
+
+		if result.Err != nil {
+			span.RecordError(result.Err)
+			// Transport failures have no status, so their description is the error itself
+			description := http.StatusText(result.StatusCode)
+			if result.StatusCode == 0 {
+				description = result.Err.Error()
+			}
+			span.SetStatus(codes.Error, description)
+		}
+
+		span.End()
+	}
+}
+
+// PropagationMiddleware injects the current trace context into every request so CCP calls can be
+// joined with server side traces. A nil propagator uses the global propagator
+func PropagationMiddleware(propagator propagation.TextMapPropagator) ccp.Middleware {
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return ccp.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+			p := propagator
+			if p == nil {
+				p = otel.GetTextMapPropagator()
+			}
+
+			header := req.Header.Clone()
+			p.Inject(req.Context(), propagation.HeaderCarrier(header))
+
+			req = req.WithContext(req.Context())
+			req.Header = header
+
+			return next.RoundTrip(req)
+		})
+	}
+}
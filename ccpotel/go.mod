@@ -0,0 +1,19 @@
+module github.com/conmurphy/ccp-clientlibrary-go/ccpotel
+
+go 1.20
+
+require (
+	github.com/conmurphy/ccp-clientlibrary-go v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	gopkg.in/validator.v2 v2.0.0-20191107172027-c3144fdedc21 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/conmurphy/ccp-clientlibrary-go => ..
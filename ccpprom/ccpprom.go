@@ -0,0 +1,97 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Package ccpprom records Prometheus metrics for the calls made by a ccp.Client
+package ccpprom
+
+import (
+	"strconv"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements ccp.Metrics with the following collectors, labelled by HTTP method and endpoint
+// template
+//
+//	ccp_client_requests_total{method,endpoint,code}
+//	ccp_client_request_errors_total{method,endpoint}
+//	ccp_client_request_duration_seconds{method,endpoint}
+//	ccp_client_request_retries_total{method,endpoint}
+type Metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+}
+
+// NewMetrics creates the collectors and registers them with the registerer, or the default registerer
+// when nil
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ccp_client",
+			Name:      "requests_total",
+			Help:      "CCP API calls by method, endpoint and status code.",
+		}, []string{"method", "endpoint", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ccp_client",
+			Name:      "request_errors_total",
+			Help:      "CCP API calls which failed or returned an error status.",
+		}, []string{"method", "endpoint"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "ccp_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of CCP API calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ccp_client",
+			Name:      "request_retries_total",
+			Help:      "Requests resent after the first attempt.",
+		}, []string{"method", "endpoint"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.duration, m.retries} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) ObserveRequest(info ccp.RequestInfo, result ccp.RequestResult) {
+
+	code := "none"
+	if result.StatusCode != 0 {
+		code = strconv.Itoa(result.StatusCode)
+	}
+
+	m.requests.WithLabelValues(info.Method, info.Endpoint, code).Inc()
+	m.duration.WithLabelValues(info.Method, info.Endpoint).Observe(result.Duration.Seconds())
+
+	if result.Err != nil {
+		m.errors.WithLabelValues(info.Method, info.Endpoint).Inc()
+	}
+	if result.Retries > 0 {
+		m.retries.WithLabelValues(info.Method, info.Endpoint).Add(float64(result.Retries))
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/conmurphy/ccp-clientlibrary-go/ccpprom
+
+go 1.20
+
+require (
+	github.com/conmurphy/ccp-clientlibrary-go v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.19.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/validator.v2 v2.0.0-20191107172027-c3144fdedc21 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/conmurphy/ccp-clientlibrary-go => ..
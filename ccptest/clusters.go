@@ -0,0 +1,437 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// cluster is a stored cluster together with the time of its last state change
+type cluster struct {
+	ccp.Cluster
+	changed time.Time
+}
+
+// AddCluster stores a cluster directly, bypassing the API. The UUID is generated when missing and the
+// state defaults to READY. The stored cluster is returned
+func (s *Server) AddCluster(c ccp.Cluster) ccp.Cluster {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.UUID == nil {
+		c.UUID = ccp.String(newID())
+	}
+	if c.State == nil {
+		c.State = ccp.String(ccp.StateReady)
+	}
+	if c.Nodes == nil {
+		c.Nodes = buildNodes(&c, *c.State)
+	}
+
+	stored := &cluster{changed: s.Now()}
+	clone(c, &stored.Cluster)
+	s.clusters = append(s.clusters, stored)
+
+	return s.copyCluster(stored)
+}
+
+// SetClusterState changes the state of a cluster and its nodes, e.g. to ERROR
+func (s *Server) SetClusterState(uuid, state string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCluster(uuid)
+	if c == nil {
+		return fmt.Errorf("Cluster %s not found", uuid)
+	}
+
+	s.setState(c, state)
+
+	return nil
+}
+
+// Clusters returns a copy of every stored cluster
+func (s *Server) Clusters() []ccp.Cluster {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	clusters := []ccp.Cluster{}
+	for _, c := range s.clusters {
+		clusters = append(clusters, s.copyCluster(c))
+	}
+
+	return clusters
+}
+
+func (s *Server) routeClusters(w http.ResponseWriter, r *request) {
+
+	s.advance()
+
+	switch {
+	case r.match("GET", "clusters"):
+		clusters := []ccp.Cluster{}
+		for _, c := range s.clusters {
+			clusters = append(clusters, s.copyCluster(c))
+		}
+		writeJSON(w, http.StatusOK, clusters)
+
+	case r.match("POST", "clusters"):
+		s.createCluster(w, r)
+
+	case r.match("GET", "clusters", "*"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.copyCluster(c))
+
+	case r.match("PATCH", "clusters", "*"):
+		s.patchCluster(w, r)
+
+	case r.match("DELETE", "clusters", "*"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		if *c.State != ccp.StateDeleting {
+			s.setState(c, ccp.StateDeleting)
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.match("GET", "clusters", "*", "health"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, clusterHealth(c))
+
+	case r.match("GET", "clusters", "*", "authz"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		authList := []string{}
+		if c.AuthList != nil {
+			authList = *c.AuthList
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"auth_list": authList})
+
+	case r.match("GET", "clusters", "*", "dashboard"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		fmt.Fprintf(w, "https://%s:30443/", masterIP(c))
+
+	case r.match("GET", "clusters", "*", "env"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		fmt.Fprint(w, kubeconfig(c))
+
+	case r.match("GET", "clusters", "*", "helmcharts"):
+		c := s.findCluster(r.path[1])
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		chart := ccp.HelmChart{}
+		if c.HelmCharts != nil && len(*c.HelmCharts) > 0 {
+			chart = (*c.HelmCharts)[0]
+		}
+		writeJSON(w, http.StatusOK, chart)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *request) {
+
+	var c ccp.Cluster
+
+	if err := decodeBody(r, &c); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if c.Name == nil || *c.Name == "" {
+		writeError(w, http.StatusBadRequest, "Cluster name is required")
+		return
+	}
+	for _, existing := range s.clusters {
+		if *existing.Name == *c.Name {
+			writeError(w, http.StatusConflict, fmt.Sprintf("Cluster with name %s already exists", *c.Name))
+			return
+		}
+	}
+	if c.ProviderClientConfigUUID != nil && s.findProvider(*c.ProviderClientConfigUUID) == nil {
+		writeError(w, http.StatusBadRequest, "Provider client config not found")
+		return
+	}
+	if c.Template != nil && (c.MasterNodePool != nil || c.WorkerNodePool != nil) {
+		writeError(w, http.StatusBadRequest, "Cluster level template cannot be provided when master_node_pool and worker_node_pool are provided")
+		return
+	}
+
+	c.UUID = ccp.String(newID())
+	c.State = ccp.String(ccp.StateCreating)
+	c.Nodes = buildNodes(&c, ccp.StateCreating)
+	c.MasterVIP = ccp.String(fmt.Sprintf("10.20.%d.10", len(s.clusters)%250))
+	c.ClusterEnvURL = ccp.String("/2/clusters/" + *c.UUID + "/env")
+	c.ClusterDashboardURL = ccp.String("/2/clusters/" + *c.UUID + "/dashboard")
+
+	stored := &cluster{changed: s.Now()}
+	clone(c, &stored.Cluster)
+	s.clusters = append(s.clusters, stored)
+
+	writeJSON(w, http.StatusCreated, s.copyCluster(stored))
+}
+
+// patchCluster merges the top level fields of the request into the stored cluster
+func (s *Server) patchCluster(w http.ResponseWriter, r *request) {
+
+	c := s.findCluster(r.path[1])
+	if c == nil {
+		writeError(w, http.StatusNotFound, "Cluster not found")
+		return
+	}
+	if *c.State != ccp.StateReady {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Cluster %s is %s and cannot be modified", *c.Name, *c.State))
+		return
+	}
+
+	var patch map[string]json.RawMessage
+
+	if err := decodeBody(r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if raw, ok := patch["name"]; ok {
+		var name string
+		if json.Unmarshal(raw, &name) != nil || name != *c.Name {
+			writeError(w, http.StatusBadRequest, "Cluster name cannot be changed")
+			return
+		}
+	}
+
+	var current map[string]json.RawMessage
+	clone(c.Cluster, &current)
+
+	for k, v := range patch {
+		switch k {
+		case "uuid", "state", "nodes":
+			continue
+		}
+		current[k] = v
+	}
+
+	var updated ccp.Cluster
+	clone(current, &updated)
+
+	if updated.Workers != nil && (c.Workers == nil || *updated.Workers != *c.Workers) {
+		updated.Nodes = buildNodes(&updated, ccp.StateReady)
+	}
+
+	c.Cluster = updated
+
+	writeJSON(w, http.StatusOK, s.copyCluster(c))
+}
+
+// advance moves clusters through their state transitions and removes deleted clusters
+func (s *Server) advance() {
+
+	now := s.Now()
+
+	remaining := s.clusters[:0]
+
+	for _, c := range s.clusters {
+		switch *c.State {
+		case ccp.StateCreating:
+			if !now.Before(c.changed.Add(s.ProvisionDelay)) {
+				s.setState(c, ccp.StateReady)
+			}
+		case ccp.StateDeleting:
+			if !now.Before(c.changed.Add(s.DeleteDelay)) {
+				continue
+			}
+		}
+		remaining = append(remaining, c)
+	}
+
+	s.clusters = remaining
+}
+
+func (s *Server) setState(c *cluster, state string) {
+
+	c.State = ccp.String(state)
+	c.changed = s.Now()
+
+	if c.Nodes != nil {
+		for i := range *c.Nodes {
+			(*c.Nodes)[i].State = ccp.String(state)
+		}
+	}
+}
+
+// findCluster finds a cluster by UUID or name
+func (s *Server) findCluster(id string) *cluster {
+
+	for _, c := range s.clusters {
+		if *c.UUID == id || (c.Name != nil && *c.Name == id) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) copyCluster(c *cluster) ccp.Cluster {
+
+	var out ccp.Cluster
+	clone(c.Cluster, &out)
+
+	return out
+}
+
+// buildNodes creates the master and worker nodes of a cluster
+func buildNodes(c *ccp.Cluster, state string) *[]ccp.Node {
+
+	var masters, workers int64 = 1, 0
+	if c.Masters != nil {
+		masters = *c.Masters
+	}
+	if c.Workers != nil {
+		workers = *c.Workers
+	}
+
+	name := ""
+	if c.Name != nil {
+		name = *c.Name
+	}
+
+	nodes := []ccp.Node{}
+
+	for i := int64(0); i < masters+workers; i++ {
+		role := "worker"
+		if i < masters {
+			role = "master"
+		}
+		nodes = append(nodes, ccp.Node{
+			UUID:              ccp.String(newID()),
+			Name:              ccp.String(fmt.Sprintf("%s-%s-%d", name, role, i)),
+			PrivateIP:         ccp.String(fmt.Sprintf("10.10.0.%d", 10+i)),
+			PublicIP:          ccp.String(fmt.Sprintf("192.168.0.%d", 10+i)),
+			IsMaster:          ccp.Bool(i < masters),
+			State:             ccp.String(state),
+			KubernetesVersion: c.KubernetesVersion,
+		})
+	}
+
+	return &nodes
+}
+
+func masterIP(c *cluster) string {
+
+	if c.MasterVIP != nil {
+		return *c.MasterVIP
+	}
+	if c.Nodes != nil {
+		for _, n := range *c.Nodes {
+			if n.IsMaster != nil && *n.IsMaster && n.PublicIP != nil {
+				return *n.PublicIP
+			}
+		}
+	}
+
+	return "127.0.0.1"
+}
+
+func clusterHealth(c *cluster) ccp.ClusterHealth {
+
+	nodes := []ccp.NodeStatus{}
+	ready := int64(0)
+
+	if c.Nodes != nil {
+		for _, n := range *c.Nodes {
+			status := "True"
+			if *n.State != ccp.StateReady {
+				status = "False"
+			} else {
+				ready++
+			}
+			nodes = append(nodes, ccp.NodeStatus{
+				NodeName:      n.Name,
+				NodeCondition: ccp.String("Ready"),
+				NodeStatus:    ccp.String(status),
+			})
+		}
+	}
+
+	total := "Healthy"
+	if ready != int64(len(nodes)) {
+		total = "Unhealthy"
+	}
+
+	return ccp.ClusterHealth{
+		TotalSystemHealth: ccp.String(total),
+		CurrentNodes:      ccp.Int64(ready),
+		ExpectedNodes:     ccp.Int64(int64(len(nodes))),
+		NodesStatus:       &nodes,
+		PodStatusList:     &[]ccp.PodStatusList{},
+	}
+}
+
+func kubeconfig(c *cluster) string {
+
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[2]s:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s-admin
+current-context: %[1]s
+users:
+- name: %[1]s-admin
+  user:
+    token: fake-token-%[3]s
+`, *c.Name, masterIP(c), *c.UUID)
+}
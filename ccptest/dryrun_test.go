@@ -0,0 +1,213 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccptest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+const dryRunSecret = "s3cret-value"
+
+// dryRunCluster passes the validation of AddCluster and AddClusterBasic and holds dryRunSecret in every
+// sensitive field
+func dryRunCluster() *ccp.Cluster {
+
+	return &ccp.Cluster{
+		Name:                      ccp.String("demo"),
+		ProviderClientConfigUUID:  ccp.String("vsphere-1"),
+		Networks:                  &[]string{"net-1"},
+		Datacenter:                ccp.String("dc-1"),
+		Datastore:                 ccp.String("datastore-1"),
+		Cluster:                   ccp.String("cluster-1"),
+		ResourcePool:              ccp.String("pool-1"),
+		Workers:                   ccp.Int64(2),
+		Masters:                   ccp.Int64(1),
+		SSHUser:                   ccp.String("ccpuser"),
+		SSHKey:                    ccp.String("ssh-rsa AAAA"),
+		SSHPassword:               ccp.String(dryRunSecret),
+		CCPPrivateSSHKey:          ccp.String(dryRunSecret),
+		IsHarborEnabled:           ccp.Bool(true),
+		HarborAdminServerPassword: ccp.String(dryRunSecret),
+		IsIstioEnabled:            ccp.Bool(false),
+		Template:                  ccp.String("ccp-tenant-image"),
+		KubernetesVersion:         ccp.String("1.10.1"),
+		Deployer: &ccp.Deployer{
+			ProviderType: ccp.String("vsphere"),
+			Provider:     &ccp.Provider{VsphereClientConfigUUID: ccp.String("vsphere-1")},
+		},
+		NetworkPlugin: &ccp.NetworkPlugin{Name: ccp.String("contiv-vpp")},
+		Infra: &ccp.Infra{
+			Datacenter:   ccp.String("dc-1"),
+			Datastore:    ccp.String("datastore-1"),
+			Cluster:      ccp.String("cluster-1"),
+			Networks:     &[]string{"net-1"},
+			ResourcePool: ccp.String("pool-1"),
+		},
+		WorkerNodePool: &ccp.WorkerNodePool{VCPUs: ccp.Int64(2), Memory: ccp.Int64(16384), Template: ccp.String("ccp-tenant-image")},
+		MasterNodePool: &ccp.MasterNodePool{VCPUs: ccp.Int64(2), Memory: ccp.Int64(8192), Template: ccp.String("ccp-tenant-image")},
+	}
+}
+
+func TestDryRun(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		mutate func(ctx context.Context, c *ccp.Client) error
+		method string
+		path   string
+		// reads are the requests which are still sent, as "METHOD path"
+		reads []string
+	}{
+		{
+			name: "AddCluster",
+			mutate: func(ctx context.Context, c *ccp.Client) error {
+				_, err := c.AddClusterContext(ctx, dryRunCluster())
+				return err
+			},
+			method: "POST",
+			path:   "/2/clusters",
+		},
+		{
+			name: "AddClusterBasic",
+			mutate: func(ctx context.Context, c *ccp.Client) error {
+				_, err := c.AddClusterBasicContext(ctx, dryRunCluster())
+				return err
+			},
+			method: "POST",
+			path:   "/2/clusters",
+			reads:  []string{"GET /2/providerclientconfigs"},
+		},
+		{
+			name: "PatchCluster",
+			mutate: func(ctx context.Context, c *ccp.Client) error {
+				_, err := c.PatchClusterContext(ctx, &ccp.Cluster{UUID: ccp.String("1234"), SSHPassword: ccp.String(dryRunSecret)})
+				return err
+			},
+			method: "PATCH",
+			path:   "/2/clusters/1234",
+		},
+		{
+			name:   "DeleteCluster",
+			mutate: func(ctx context.Context, c *ccp.Client) error { return c.DeleteClusterContext(ctx, "1234") },
+			method: "DELETE",
+			path:   "/2/clusters/1234",
+		},
+		{
+			name: "AddUser",
+			mutate: func(ctx context.Context, c *ccp.Client) error {
+				_, err := c.AddUserContext(ctx, &ccp.User{
+					Username: ccp.String("jsmith"),
+					Password: ccp.String(dryRunSecret),
+					Role:     ccp.String("Developer"),
+				})
+				return err
+			},
+			method: "POST",
+			path:   "/2/localusers",
+		},
+		{
+			name: "PatchUser",
+			mutate: func(ctx context.Context, c *ccp.Client) error {
+				_, err := c.PatchUserContext(ctx, &ccp.User{
+					Username: ccp.String("jsmith"),
+					Password: ccp.String(dryRunSecret),
+					Token:    ccp.String(dryRunSecret),
+				})
+				return err
+			},
+			method: "PATCH",
+			path:   "/2/localusers/jsmith",
+		},
+		{
+			name:   "DeleteUser",
+			mutate: func(ctx context.Context, c *ccp.Client) error { return c.DeleteUserContext(ctx, "jsmith") },
+			method: "DELETE",
+			path:   "/2/localusers/jsmith",
+		},
+	}
+
+	for _, test := range tests {
+		// Dry run is enabled on the client or for a single call with WithDryRun
+		for _, perCall := range []bool{false, true} {
+			name := test.name
+			srv, client, _ := newServer(t)
+
+			ctx := context.Background()
+			if perCall {
+				name += " WithDryRun"
+				ctx = ccp.WithDryRun(ctx)
+			} else {
+				client.DryRun = true
+			}
+
+			sent := len(srv.Requests())
+			err := test.mutate(ctx, client)
+
+			var reads []string
+			for _, req := range srv.Requests()[sent:] {
+				reads = append(reads, req.Method+" "+req.Path)
+			}
+
+			srv.Close()
+
+			if strings.Join(reads, ", ") != strings.Join(test.reads, ", ") {
+				t.Errorf("%s: server received %q, want %q", name, reads, test.reads)
+			}
+
+			planned, ok := ccp.DryRunRequest(err)
+			if !ok {
+				t.Errorf("%s: returned %v, want a DryRunError", name, err)
+				continue
+			}
+			if planned.Method != test.method || !strings.HasSuffix(planned.URL, test.path) {
+				t.Errorf("%s: planned %s %s, want %s %s", name, planned.Method, planned.URL, test.method, test.path)
+			}
+			if strings.Contains(string(planned.Body), dryRunSecret) {
+				t.Errorf("%s: secret not redacted from %s", name, planned.Body)
+			}
+		}
+	}
+}
+
+func TestDryRunRedactsBody(t *testing.T) {
+
+	srv, client, _ := newServer(t)
+	defer srv.Close()
+
+	client.DryRun = true
+
+	_, err := client.AddCluster(dryRunCluster())
+
+	planned, ok := ccp.DryRunRequest(err)
+	if !ok {
+		t.Fatalf("AddCluster returned %v, want a DryRunError", err)
+	}
+
+	body := string(planned.Body)
+	for _, key := range []string{"ssh_password", "ccp_private_ssh_key", "harbor_admin_server_password"} {
+		if !strings.Contains(body, `"`+key+`":"[REDACTED]"`) {
+			t.Errorf("%s is not redacted in %s", key, body)
+		}
+	}
+	// Only the sensitive fields are redacted
+	if !strings.Contains(body, `"ssh_key":"ssh-rsa AAAA"`) {
+		t.Errorf("ssh_key was removed from %s", body)
+	}
+}
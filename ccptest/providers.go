@@ -0,0 +1,291 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccptest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// Inventory is the vSphere inventory browsed through a provider client config
+type Inventory struct {
+	Datacenters map[string]Datacenter
+}
+
+// Datacenter is a vSphere datacenter. Clusters maps each cluster name to its resource pools
+type Datacenter struct {
+	Clusters   map[string][]string
+	VMs        []string
+	Networks   []string
+	Datastores []string
+}
+
+// DefaultInventory returns the inventory of the provider client config created by NewServer
+func DefaultInventory() Inventory {
+
+	return Inventory{
+		Datacenters: map[string]Datacenter{
+			"dc1": {
+				Clusters: map[string][]string{
+					"cluster1": {"cluster1/Resources"},
+				},
+				VMs:        []string{"ccp-tenant-image-1.10.1-ubuntu16-1.5.0"},
+				Networks:   []string{"VM Network", "ccp-network/ccp-network-port-group"},
+				Datastores: []string{"datastore1"},
+			},
+		},
+	}
+}
+
+type provider struct {
+	ccp.ProviderClientConfig
+	inventory Inventory
+}
+
+// AddProviderClientConfig stores a provider client config with its vSphere inventory. The UUID is
+// generated when missing and the stored config is returned
+func (s *Server) AddProviderClientConfig(p ccp.ProviderClientConfig, inventory Inventory) ccp.ProviderClientConfig {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.UUID == nil {
+		p.UUID = ccp.String(newID())
+	}
+
+	stored := &provider{inventory: inventory}
+	clone(p, &stored.ProviderClientConfig)
+	s.providers = append(s.providers, stored)
+
+	return p
+}
+
+// AddACIProfile stores an ACI profile. The UUID is generated when missing
+func (s *Server) AddACIProfile(profile ccp.ACIProfile) ccp.ACIProfile {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if profile.UUID == nil {
+		profile.UUID = ccp.String(newID())
+	}
+
+	var stored ccp.ACIProfile
+	clone(profile, &stored)
+	s.aciProfiles = append(s.aciProfiles, stored)
+
+	return profile
+}
+
+// SetLDAPSetup sets the LDAP configuration returned by the server
+func (s *Server) SetLDAPSetup(setup ccp.LDAPSetup) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ldap = &setup
+}
+
+func (s *Server) findProvider(uuid string) *provider {
+
+	for _, p := range s.providers {
+		if *p.UUID == uuid {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) routeProviders(w http.ResponseWriter, r *request) {
+
+	if r.match("GET", "providerclientconfigs") {
+		configs := []ccp.ProviderClientConfig{}
+		for _, p := range s.providers {
+			configs = append(configs, p.ProviderClientConfig)
+		}
+		writeJSON(w, http.StatusOK, configs)
+		return
+	}
+
+	if len(r.path) < 2 || r.Method != "GET" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	p := s.findProvider(r.path[1])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "Provider client config not found")
+		return
+	}
+
+	switch {
+	case r.match("GET", "providerclientconfigs", "*"):
+		writeJSON(w, http.StatusOK, p.ProviderClientConfig)
+
+	case r.match("GET", "providerclientconfigs", "*", "clusters"):
+		s.advance()
+		clusters := []ccp.Cluster{}
+		for _, c := range s.clusters {
+			if c.ProviderClientConfigUUID != nil && *c.ProviderClientConfigUUID == *p.UUID {
+				clusters = append(clusters, s.copyCluster(c))
+			}
+		}
+		writeJSON(w, http.StatusOK, clusters)
+
+	case r.match("GET", "providerclientconfigs", "*", "vsphere", "datacenter"):
+		names := []string{}
+		for name := range p.inventory.Datacenters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeJSON(w, http.StatusOK, ccp.Vsphere{Datacenters: &names})
+
+	case r.match("GET", "providerclientconfigs", "*", "vsphere", "datacenter", "*", "*"),
+		r.match("GET", "providerclientconfigs", "*", "vsphere", "datacenter", "*", "cluster", "*", "pool"):
+		s.browseDatacenter(w, r, p)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) browseDatacenter(w http.ResponseWriter, r *request, p *provider) {
+
+	dc, ok := p.inventory.Datacenters[r.path[4]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Datacenter not found")
+		return
+	}
+
+	if len(r.path) == 8 {
+		pools, ok := dc.Clusters[r.path[6]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Cluster not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, ccp.Vsphere{Pools: nonNil(pools)})
+		return
+	}
+
+	switch r.path[5] {
+	case "cluster":
+		names := []string{}
+		for name := range dc.Clusters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeJSON(w, http.StatusOK, ccp.Vsphere{Clusters: &names})
+	case "vm":
+		writeJSON(w, http.StatusOK, ccp.Vsphere{VMs: nonNil(dc.VMs)})
+	case "network":
+		writeJSON(w, http.StatusOK, ccp.Vsphere{Networks: nonNil(dc.Networks)})
+	case "datastore":
+		writeJSON(w, http.StatusOK, ccp.Vsphere{Datastores: nonNil(dc.Datastores)})
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func nonNil(list []string) *[]string {
+
+	if list == nil {
+		list = []string{}
+	}
+
+	return &list
+}
+
+func (s *Server) routeACIProfiles(w http.ResponseWriter, r *request) {
+
+	if !r.match("GET", "aci_profiles") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	profiles := append([]ccp.ACIProfile{}, s.aciProfiles...)
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+func (s *Server) routeLDAP(w http.ResponseWriter, r *request) {
+
+	if !r.match("GET", "ldap", "setup") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if s.ldap == nil {
+		writeError(w, http.StatusNotFound, "LDAP is not configured")
+		return
+	}
+
+	setup := *s.ldap
+	setup.ServiceAccountPassword = nil
+	writeJSON(w, http.StatusOK, setup)
+}
+
+func (s *Server) routeRBAC(w http.ResponseWriter, r *request) {
+
+	if !r.match("GET", "rbac") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ccp.Role{Role: s.users[r.user].Role})
+}
+
+func (s *Server) routeSystem(w http.ResponseWriter, r *request) {
+
+	if !r.match("GET", "system", "health") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if s.health != nil {
+		writeJSON(w, http.StatusOK, s.health)
+		return
+	}
+
+	now := ccp.String(s.Now().UTC().Format(time.RFC3339))
+
+	writeJSON(w, http.StatusOK, ccp.Health{
+		TotalSystemHealth: ccp.String("Healthy"),
+		CurrentNodes:      ccp.Int64(1),
+		ExpectedNodes:     ccp.Int64(1),
+		NodesStatus: &[]ccp.NodeStatus{{
+			NodeName:           ccp.String("ccp-control-master-0"),
+			NodeCondition:      ccp.String("Ready"),
+			NodeStatus:         ccp.String("True"),
+			LastTransitionTime: now,
+		}},
+		PodStatusList: &[]ccp.PodStatusList{{
+			PodName:            ccp.String("cx-api-0"),
+			PodCondition:       ccp.String("Ready"),
+			PodStatus:          ccp.String("True"),
+			LastTransitionTime: now,
+		}},
+	})
+}
+
+func (s *Server) livenessHealth(w http.ResponseWriter, r *request) {
+
+	writeJSON(w, http.StatusOK, ccp.LivenessHealth{
+		CXVersion:      ccp.String("1.5.0"),
+		TimeOnMgmtHost: ccp.String(s.Now().UTC().Format(time.RFC3339)),
+	})
+}
@@ -0,0 +1,429 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Package ccptest provides an in-memory fake of the CCP v2 API for testing code built on the ccp
+// package without a CCP control plane.
+//
+//	srv := ccptest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.NewClient()
+//	err := client.Login(nil)
+package ccptest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// Default credentials of the administrator account created by NewServer
+const (
+	DefaultUsername = "admin"
+	DefaultPassword = "admin"
+)
+
+// SessionCookie is the name of the cookie set by a successful login
+const SessionCookie = "ccp_session"
+
+// Server is a fake CCP control plane. Clusters move from CREATING to READY once ProvisionDelay has
+// passed and disappear once DeleteDelay has passed after a delete. Every endpoint other than login and
+// liveness health requires a session cookie or an API token
+type Server struct {
+	// URL of the server, suitable for ccp.Client.BaseURL
+	URL string
+
+	// ProvisionDelay and DeleteDelay control how long clusters stay in the CREATING and DELETING
+	// states. With the default of zero the next read after a create or delete sees the new state
+	ProvisionDelay time.Duration
+	DeleteDelay    time.Duration
+
+	// Now returns the current time and may be replaced to control state transitions
+	Now func() time.Time
+
+	server *httptest.Server
+
+	mu          sync.Mutex
+	clusters    []*cluster
+	users       map[string]*ccp.User
+	passwords   map[string]string
+	sessions    map[string]string
+	tokens      map[string]string
+	providers   []*provider
+	aciProfiles []ccp.ACIProfile
+	ldap        *ccp.LDAPSetup
+	health      *ccp.Health
+	faults      []*Fault
+	requests    []Request
+}
+
+// Fault makes matching requests fail. Empty Method and Path match every request
+type Fault struct {
+	Method string
+	// Path is matched as a prefix of the unescaped request path, e.g. "/2/clusters"
+	Path string
+
+	StatusCode int
+	Body       string
+	// Delay is applied before the response is written
+	Delay time.Duration
+	// Times limits how many requests fail. Zero fails every matching request
+	Times int
+
+	hits int
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// NewServer starts a fake CCP with an administrator account and a single vSphere provider client
+// config. The server must be closed when no longer needed
+func NewServer() *Server {
+
+	s := &Server{
+		Now:       time.Now,
+		users:     map[string]*ccp.User{},
+		passwords: map[string]string{},
+		sessions:  map[string]string{},
+		tokens:    map[string]string{},
+	}
+
+	s.AddUser(ccp.User{
+		Username:  ccp.String(DefaultUsername),
+		Password:  ccp.String(DefaultPassword),
+		Role:      ccp.String("SysAdmin"),
+		FirstName: ccp.String("Admin"),
+		Disable:   ccp.Bool(false),
+	})
+
+	s.AddProviderClientConfig(ccp.ProviderClientConfig{
+		Name: ccp.String("vsphere"),
+		Type: ccp.Int64(1),
+		Config: &ccp.Config{
+			IP:       ccp.String("10.10.10.10"),
+			Port:     ccp.Int64(443),
+			Username: ccp.String("administrator@vsphere.local"),
+		},
+	}, DefaultInventory())
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// NewClient returns a client for the server using the default administrator credentials. Login must
+// still be called before other requests
+func (s *Server) NewClient() *ccp.Client {
+	return ccp.NewClient(DefaultUsername, DefaultPassword, s.URL)
+}
+
+// InjectFault adds a fault. Faults are checked in the order they were added
+func (s *Server) InjectFault(f Fault) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults
+func (s *Server) ClearFaults() {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// SetHealth replaces the response of the system health endpoint
+func (s *Server) SetHealth(health ccp.Health) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health = &health
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	segments, err := splitPath(r.URL.EscapedPath())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header,
+		Body:   body,
+	})
+
+	fault := s.matchFault(r)
+
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			time.Sleep(fault.Delay)
+		}
+		if fault.StatusCode != 0 {
+			writeError(w, fault.StatusCode, fault.Body)
+			return
+		}
+	}
+
+	if len(segments) < 2 || segments[0] != "2" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	req := &request{Request: r, body: body, path: segments[1:]}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.route(w, req)
+}
+
+// request is a request being served, with the path split after the version prefix
+type request struct {
+	*http.Request
+	body []byte
+	path []string
+	user string
+}
+
+func (r *request) match(method string, pattern ...string) bool {
+
+	if r.Method != method || len(r.path) != len(pattern) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "*" && p != r.path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) route(w http.ResponseWriter, r *request) {
+
+	switch {
+	case r.match("POST", "system", "login"):
+		s.login(w, r)
+		return
+	case r.match("GET", "system", "livenessHealth"):
+		s.livenessHealth(w, r)
+		return
+	}
+
+	if !s.authenticate(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch r.path[0] {
+	case "system":
+		s.routeSystem(w, r)
+	case "clusters":
+		s.routeClusters(w, r)
+	case "localusers":
+		s.routeUsers(w, r)
+	case "providerclientconfigs":
+		s.routeProviders(w, r)
+	case "aci_profiles":
+		s.routeACIProfiles(w, r)
+	case "ldap":
+		s.routeLDAP(w, r)
+	case "rbac":
+		s.routeRBAC(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// matchFault returns the first fault matching the request, if any
+func (s *Server) matchFault(r *http.Request) *Fault {
+
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+
+		f.hits++
+		if f.Times > 0 && f.hits >= f.Times {
+			s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+func (s *Server) login(w http.ResponseWriter, r *request) {
+
+	form, _ := url.ParseQuery(string(r.body))
+
+	username := form.Get("username")
+	password := form.Get("password")
+
+	// Older clients sent the credentials in the query string
+	if username == "" {
+		username = r.URL.Query().Get("username")
+		password = r.URL.Query().Get("password")
+	}
+
+	user, ok := s.users[username]
+	if !ok || s.passwords[username] != password || (user.Disable != nil && *user.Disable) {
+		writeError(w, http.StatusUnauthorized, "Invalid username or password")
+		return
+	}
+
+	session := newID()
+	s.sessions[session] = username
+
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: session, Path: "/", HttpOnly: true})
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticate checks the session cookie or API token of the request and records the user
+func (s *Server) authenticate(r *request) bool {
+
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		if user, ok := s.sessions[cookie.Value]; ok {
+			r.user = user
+			return true
+		}
+	}
+
+	token := r.Header.Get(ccp.AuthTokenHeader)
+	if auth := r.Header.Get(ccp.AuthorizationHeader); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	if user, ok := s.tokens[token]; ok && token != "" {
+		r.user = user
+		return true
+	}
+
+	return false
+}
+
+// splitPath splits an escaped path into unescaped segments, so escaped slashes stay within a segment
+func splitPath(escaped string) ([]string, error) {
+
+	var segments []string
+
+	for _, segment := range strings.Split(strings.Trim(escaped, "/"), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, unescaped)
+	}
+
+	return segments, nil
+}
+
+func decodeBody(r *request, v interface{}) error {
+
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return fmt.Errorf("Invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+
+	fmt.Fprint(w, message)
+}
+
+// newID returns a random UUID formatted identifier
+func newID() string {
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	h := hex.EncodeToString(b)
+
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// clone deep copies v into out through JSON, the same way values cross the API
+func clone(v, out interface{}) {
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(j, out); err != nil {
+		panic(err)
+	}
+}
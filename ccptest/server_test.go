@@ -0,0 +1,282 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccptest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// clock is a fake time source for Server.Now
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newServer(t *testing.T) (*Server, *ccp.Client, *clock) {
+
+	srv := NewServer()
+
+	c := &clock{now: time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)}
+	srv.Now = c.Now
+
+	client := srv.NewClient()
+	if err := client.Login(nil); err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+
+	return srv, client, c
+}
+
+func TestClusterProvisioning(t *testing.T) {
+
+	srv, client, clock := newServer(t)
+	defer srv.Close()
+	srv.ProvisionDelay = time.Minute
+
+	srv.AddCluster(ccp.Cluster{
+		Name:    ccp.String("demo"),
+		State:   ccp.String(ccp.StateCreating),
+		Workers: ccp.Int64(2),
+		Masters: ccp.Int64(1),
+	})
+
+	steps := []struct {
+		after time.Duration
+		state string
+	}{
+		{after: 0, state: ccp.StateCreating},
+		{after: 59 * time.Second, state: ccp.StateCreating},
+		{after: time.Second, state: ccp.StateReady},
+	}
+
+	for _, step := range steps {
+		clock.now = clock.now.Add(step.after)
+
+		cluster, err := client.GetCluster("demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *cluster.State != step.state {
+			t.Fatalf("Cluster is %s at %s, want %s", *cluster.State, clock.now, step.state)
+		}
+		for _, node := range *cluster.Nodes {
+			if *node.State != step.state {
+				t.Errorf("Node %s is %s, want %s", *node.Name, *node.State, step.state)
+			}
+		}
+	}
+}
+
+func TestClusterDeletion(t *testing.T) {
+
+	srv, client, clock := newServer(t)
+	defer srv.Close()
+	srv.DeleteDelay = time.Minute
+
+	cluster := srv.AddCluster(ccp.Cluster{Name: ccp.String("demo"), Workers: ccp.Int64(1), Masters: ccp.Int64(1)})
+
+	if err := client.DeleteCluster(*cluster.UUID); err != nil {
+		t.Fatal(err)
+	}
+
+	deleting, err := client.GetCluster("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *deleting.State != ccp.StateDeleting {
+		t.Fatalf("Cluster is %s after delete, want %s", *deleting.State, ccp.StateDeleting)
+	}
+
+	// Deleting again does not restart the delay
+	clock.now = clock.now.Add(30 * time.Second)
+	if err := client.DeleteCluster(*cluster.UUID); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+
+	if _, err := client.GetCluster("demo"); !ccp.IsNotFound(err) {
+		t.Fatalf("GetCluster after the delete delay returned %v, want a 404", err)
+	}
+	if clusters := srv.Clusters(); len(clusters) != 0 {
+		t.Errorf("Server still has %d clusters", len(clusters))
+	}
+}
+
+func TestSessionRequired(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.NewClient()
+
+	// Login and liveness health are open, everything else requires a session
+	if _, err := client.GetLivenessHealth(); err != nil {
+		t.Errorf("GetLivenessHealth without a session: %v", err)
+	}
+
+	_, err := client.GetClusters()
+	if apiErr, ok := err.(*ccp.APIError); !ok || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("GetClusters without a session returned %v, want a 401", err)
+	}
+
+	wrong := ccp.NewClient(DefaultUsername, "wrong", srv.URL)
+	if err := wrong.Login(nil); err == nil {
+		t.Error("Login with a wrong password succeeded")
+	}
+	if _, err := wrong.GetClusters(); err == nil {
+		t.Error("GetClusters after a failed login succeeded")
+	}
+
+	if err := client.Login(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetClusters(); err != nil {
+		t.Errorf("GetClusters with a session: %v", err)
+	}
+
+	// A session cookie which the server did not issue is rejected
+	forged := ccp.NewClient(DefaultUsername, DefaultPassword, srv.URL)
+	forged.Use(func(next http.RoundTripper) http.RoundTripper {
+		return ccp.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.AddCookie(&http.Cookie{Name: SessionCookie, Value: "forged"})
+			return next.RoundTrip(req)
+		})
+	})
+	if _, err := forged.GetClusters(); err == nil {
+		t.Error("GetClusters with a forged session cookie succeeded")
+	}
+}
+
+func TestIssueToken(t *testing.T) {
+
+	srv := NewServer()
+	defer srv.Close()
+
+	if _, err := srv.IssueToken("nobody"); err == nil {
+		t.Error("IssueToken succeeded for a missing user")
+	}
+
+	first, err := srv.IssueToken(DefaultUsername)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := srv.IssueToken(DefaultUsername)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		token   string
+		wantErr bool
+	}{
+		{token: second},
+		{token: first, wantErr: true},
+		{token: "forged", wantErr: true},
+	}
+
+	for _, test := range tests {
+		_, err := ccp.NewClientWithToken(srv.URL, test.token).GetClusters()
+
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("GetClusters with token %q succeeded, want a 401", test.token)
+		case !test.wantErr && err != nil:
+			t.Errorf("GetClusters with token %q: %v", test.token, err)
+		}
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+
+	srv, client, _ := newServer(t)
+	defer srv.Close()
+
+	srv.InjectFault(Fault{Method: "GET", Path: "/2/clusters", StatusCode: http.StatusServiceUnavailable, Body: "unavailable", Times: 2})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetClusters()
+		if apiErr, ok := err.(*ccp.APIError); !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Request %d returned %v, want a 503", i+1, err)
+		}
+	}
+
+	if _, err := client.GetClusters(); err != nil {
+		t.Errorf("Request after the fault was used up: %v", err)
+	}
+
+	// Other methods and paths are not affected
+	srv.InjectFault(Fault{Method: "POST", Path: "/2/clusters", StatusCode: http.StatusInternalServerError})
+	if _, err := client.GetClusters(); err != nil {
+		t.Errorf("GET with a POST fault: %v", err)
+	}
+	if _, err := client.GetUsers(); err != nil {
+		t.Errorf("Other path with a fault: %v", err)
+	}
+
+	srv.ClearFaults()
+	srv.InjectFault(Fault{StatusCode: http.StatusBadGateway})
+	if _, err := client.GetUsers(); err == nil {
+		t.Error("Request succeeded with a fault matching every request")
+	}
+
+	srv.ClearFaults()
+	if _, err := client.GetUsers(); err != nil {
+		t.Errorf("Request after ClearFaults: %v", err)
+	}
+}
+
+func TestInjectFaultDelay(t *testing.T) {
+
+	srv, client, _ := newServer(t)
+	defer srv.Close()
+
+	// A fault with only a delay slows the request down without failing it
+	srv.InjectFault(Fault{Path: "/2/clusters", Delay: 50 * time.Millisecond, Times: 1})
+
+	start := time.Now()
+	if _, err := client.GetClusters(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Request took %s, want at least the 50ms delay", elapsed)
+	}
+}
+
+func TestRequests(t *testing.T) {
+
+	srv, client, _ := newServer(t)
+	defer srv.Close()
+
+	if _, err := client.GetCluster("my cluster"); !ccp.IsNotFound(err) {
+		t.Fatalf("GetCluster returned %v, want a 404", err)
+	}
+
+	requests := srv.Requests()
+	last := requests[len(requests)-1]
+
+	if len(requests) != 2 || last.Method != "GET" || last.Path != "/2/clusters/my cluster" {
+		t.Errorf("Requests are %+v, want the login and GET /2/clusters/my cluster", requests)
+	}
+}
@@ -0,0 +1,166 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package ccptest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// AddUser stores a local user directly, bypassing the API. The password, if any, can be used to log in
+func (s *Server) AddUser(u ccp.User) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.storeUser(u)
+}
+
+// IssueToken returns a new API token for a local user, replacing any it had. CCP has no documented
+// endpoint for tokens, so they are issued directly for clients created with ccp.NewClientWithToken
+func (s *Server) IssueToken(username string) (string, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[username]; !ok {
+		return "", fmt.Errorf("User %s not found", username)
+	}
+
+	s.revokeTokens(username)
+	token := newID()
+	s.tokens[token] = username
+
+	return token, nil
+}
+
+func (s *Server) storeUser(u ccp.User) *ccp.User {
+
+	username := *u.Username
+
+	if u.Password != nil {
+		s.passwords[username] = *u.Password
+	}
+
+	var stored ccp.User
+	clone(u, &stored)
+
+	// Passwords and tokens are never returned by the API
+	stored.Password = nil
+	stored.Token = nil
+
+	s.users[username] = &stored
+
+	return &stored
+}
+
+func (s *Server) routeUsers(w http.ResponseWriter, r *request) {
+
+	switch {
+	case r.match("GET", "localusers"):
+		names := make([]string, 0, len(s.users))
+		for name := range s.users {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		users := []ccp.User{}
+		for _, name := range names {
+			users = append(users, *s.users[name])
+		}
+		writeJSON(w, http.StatusOK, users)
+
+	case r.match("POST", "localusers"):
+		var u ccp.User
+		if err := decodeBody(r, &u); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if u.Username == nil || *u.Username == "" || u.Role == nil {
+			writeError(w, http.StatusBadRequest, "UserName and Role are required")
+			return
+		}
+		if _, ok := s.users[*u.Username]; ok {
+			writeError(w, http.StatusConflict, fmt.Sprintf("User %s already exists", *u.Username))
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.storeUser(u))
+
+	case r.match("PATCH", "localusers", "*"):
+		existing, ok := s.users[r.path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		var patch ccp.User
+		if err := decodeBody(r, &patch); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		updated := *existing
+		if patch.Disable != nil {
+			updated.Disable = patch.Disable
+		}
+		if patch.Role != nil {
+			updated.Role = patch.Role
+		}
+		if patch.FirstName != nil {
+			updated.FirstName = patch.FirstName
+		}
+		if patch.LastName != nil {
+			updated.LastName = patch.LastName
+		}
+		updated.Password = patch.Password
+		writeJSON(w, http.StatusOK, s.storeUser(updated))
+
+	case r.match("DELETE", "localusers", "*"):
+		username := r.path[1]
+		if _, ok := s.users[username]; !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		delete(s.users, username)
+		delete(s.passwords, username)
+		s.revokeSessions(username)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) revokeTokens(username string) {
+
+	for token, user := range s.tokens {
+		if user == username {
+			delete(s.tokens, token)
+		}
+	}
+}
+
+func (s *Server) revokeSessions(username string) {
+
+	for session, user := range s.sessions {
+		if user == username {
+			delete(s.sessions, session)
+		}
+	}
+
+	s.revokeTokens(username)
+}
@@ -0,0 +1,314 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+var clustersCommand = &command{
+	name: "clusters",
+	sub: []*command{
+		{name: "list", usage: "clusters list", help: "List clusters", run: (*cli).clustersList},
+		{name: "get", usage: "clusters get NAME", help: "Show a cluster", run: (*cli).clustersGet},
+		{name: "create", usage: "clusters create -f FILE", help: "Create a cluster from a YAML or JSON spec file", run: (*cli).clustersCreate},
+		{name: "delete", usage: "clusters delete NAME [--yes]", help: "Delete a cluster", run: (*cli).clustersDelete},
+		{name: "scale", usage: "clusters scale NAME --workers N", help: "Change the number of workers of a cluster", run: (*cli).clustersScale},
+		{name: "wait", usage: "clusters wait NAME [--state READY] [--timeout 30m] [--interval 10s]", help: "Wait for a cluster to reach a state", run: (*cli).clustersWait},
+	},
+}
+
+var kubeconfigCommand = &command{
+	name:  "kubeconfig",
+	usage: "kubeconfig NAME [-f FILE]",
+	help:  "Print or save the kubeconfig of a cluster",
+	run:   (*cli).kubeconfig,
+}
+
+func clusterTable(clusters ...ccp.Cluster) *table {
+
+	t := &table{header: []string{"NAME", "STATE", "VERSION", "MASTERS", "WORKERS", "UUID"}}
+
+	for _, cluster := range clusters {
+		t.add(str(cluster.Name), str(cluster.State), str(cluster.KubernetesVersion), num(cluster.Masters), num(cluster.Workers), str(cluster.UUID))
+	}
+
+	return t
+}
+
+func (c *cli) clustersList(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	clusters, err := client.GetClusters()
+	if err != nil {
+		return err
+	}
+
+	return c.print(clusters, clusterTable(clusters...))
+}
+
+func (c *cli) clustersGet(args []string) error {
+
+	positional, err := c.parse(args, 1, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := c.cluster(positional[0])
+	if err != nil {
+		return err
+	}
+
+	return c.print(cluster, clusterTable(*cluster))
+}
+
+// cluster looks up a cluster by name
+func (c *cli) cluster(name string) (*ccp.Cluster, error) {
+
+	client, err := c.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := client.GetCluster(name)
+	if err != nil {
+		if ccp.IsNotFound(err) {
+			return nil, fmt.Errorf("Cluster %s not found", name)
+		}
+		return nil, err
+	}
+	if cluster == nil || cluster.UUID == nil {
+		return nil, fmt.Errorf("Cluster %s not found", name)
+	}
+
+	return cluster, nil
+}
+
+func (c *cli) clustersCreate(args []string) error {
+
+	var file string
+
+	_, err := c.parse(args, 0, 0, func(fs *flag.FlagSet) {
+		fs.StringVar(&file, "f", "", "cluster spec file")
+	})
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return usageError("Usage: ccpctl " + c.command.usage)
+	}
+
+	spec, err := ccp.LoadClusterSpec(file)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := client.AddCluster(spec)
+	if err != nil {
+		return err
+	}
+
+	return c.print(cluster, clusterTable(*cluster))
+}
+
+func (c *cli) clustersDelete(args []string) error {
+
+	var yes bool
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		fs.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	})
+	if err != nil {
+		return err
+	}
+
+	cluster, err := c.cluster(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if !yes && !c.dryRun {
+		fmt.Fprintf(c.stderr, "Delete cluster %s (%s)? [y/N] ", *cluster.Name, *cluster.UUID)
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("Delete cancelled")
+		}
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteCluster(*cluster.UUID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Cluster %s deleting\n", *cluster.Name)
+
+	return nil
+}
+
+func (c *cli) clustersScale(args []string) error {
+
+	workers := int64(-1)
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		fs.Int64Var(&workers, "workers", -1, "number of workers")
+	})
+	if err != nil {
+		return err
+	}
+	if workers < 0 {
+		return usageError("Usage: ccpctl " + c.command.usage)
+	}
+
+	cluster, err := c.cluster(positional[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	patched, err := client.PatchCluster(&ccp.Cluster{
+		UUID:    cluster.UUID,
+		Workers: ccp.Int64(workers),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.print(patched, clusterTable(*patched))
+}
+
+func (c *cli) clustersWait(args []string) error {
+
+	state := ccp.StateReady
+	timeout := 30 * time.Minute
+	interval := 10 * time.Second
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		fs.StringVar(&state, "state", state, "state to wait for, or DELETED for the cluster to be gone")
+		fs.DurationVar(&timeout, "timeout", timeout, "how long to wait")
+		fs.DurationVar(&interval, "interval", interval, "how often to check")
+	})
+	if err != nil {
+		return err
+	}
+
+	name := positional[0]
+	state = strings.ToUpper(state)
+	deadline := time.Now().Add(timeout)
+	last := ""
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	for {
+		cluster, err := client.GetCluster(name)
+
+		current := ""
+		switch {
+		case ccp.IsNotFound(err):
+			current = "DELETED"
+		case err != nil:
+			return err
+		case cluster != nil:
+			current = str(cluster.State)
+		}
+
+		if current != last {
+			fmt.Fprintf(c.stderr, "Cluster %s is %s\n", name, current)
+			last = current
+		}
+
+		if current == state {
+			if cluster != nil && state != "DELETED" {
+				return c.print(cluster, clusterTable(*cluster))
+			}
+			return nil
+		}
+		if current == ccp.StateError || (current == "DELETED" && state != "DELETED") {
+			return fmt.Errorf("Cluster %s is %s, not %s", name, current, state)
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("Timed out after %s waiting for cluster %s to be %s", timeout, name, state)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func (c *cli) kubeconfig(args []string) error {
+
+	var file string
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		fs.StringVar(&file, "f", "", "file to write the kubeconfig to")
+	})
+	if err != nil {
+		return err
+	}
+
+	cluster, err := c.cluster(positional[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	env, err := client.GetClusterEnv(*cluster.UUID)
+	if err != nil {
+		return err
+	}
+
+	if file != "" {
+		return ioutil.WriteFile(file, []byte(*env), 0600)
+	}
+
+	_, err = fmt.Fprint(c.stdout, *env)
+
+	return err
+}
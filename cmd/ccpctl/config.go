@@ -0,0 +1,289 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	keyring "github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name under which passwords are kept in the system keyring
+const keyringService = "ccpctl"
+
+// credentialsKeyring is the credential source of contexts with their password in the system keyring
+const credentialsKeyring = "keyring"
+
+func init() {
+	ccp.RegisterCredentialSource(credentialsKeyring, keyringCredentials)
+}
+
+func (c *cli) configFile() string {
+
+	if c.configPath != "" {
+		return c.configPath
+	}
+
+	return ccp.DefaultConfigPath()
+}
+
+func (c *cli) loadConfig() (*ccp.ConfigFile, error) {
+	return ccp.LoadConfig(c.configFile())
+}
+
+func (c *cli) saveConfig(cfg *ccp.ConfigFile) error {
+	return cfg.Save(c.configFile())
+}
+
+// keyringUser is the account name of a context password in the keyring
+func keyringUser(ctx *ccp.Context) string {
+	return ctx.Username + "@" + ctx.URL
+}
+
+// keyringCredentials reads the password of a context from the system keyring on every login
+func keyringCredentials(ctx *ccp.Context) (ccp.CredentialsProvider, error) {
+
+	if ctx.Username == "" {
+		return nil, fmt.Errorf("Context %s has no username, run ccpctl login", ctx.Name)
+	}
+
+	username, user := ctx.Username, keyringUser(ctx)
+
+	return ccp.CredentialsProviderFunc(func() (*ccp.Credentials, error) {
+
+		password, err := keyring.Get(keyringService, user)
+		if err == keyring.ErrNotFound {
+			return nil, fmt.Errorf("No password for %s in the keyring, run ccpctl login", user)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Reading the keyring: %v", err)
+		}
+
+		return &ccp.Credentials{Username: username, Password: password}, nil
+	}), nil
+}
+
+// newClient returns a client for the current context, logged in unless it uses a token
+func (c *cli) newClient() (*ccp.Client, error) {
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := cfg.ResolveContext(c.contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ctx.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Token == "" {
+		if err := client.Login(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.dryRun {
+		client.DryRun = true
+	}
+	c.client = client
+
+	return client, nil
+}
+
+var loginCommand = &command{
+	name:  "login",
+	usage: "login [--context NAME] [--url URL] [--username USERNAME] [--credentials keyring|env|file:PATH] [--password-stdin] [--insecure]",
+	help:  "Check credentials for a CCP and save them as a context, with the password in the keyring, and make it the current context",
+	run:   (*cli).login,
+}
+
+var contextsCommand = &command{
+	name: "contexts",
+	sub: []*command{
+		{name: "list", usage: "contexts list", help: "List the contexts of the config file", run: (*cli).contextsList},
+		{name: "use", usage: "contexts use NAME", help: "Change the current context", run: (*cli).contextsUse},
+	},
+}
+
+func (c *cli) contextsList(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"CURRENT", "NAME", "URL", "USERNAME", "CREDENTIALS"}}
+
+	for _, ctx := range cfg.Contexts {
+		current := ""
+		if ctx.Name == cfg.CurrentContext {
+			current = "*"
+		}
+		credentials := ctx.Credentials
+		if credentials == "" {
+			credentials = ccp.CredentialsEnv
+		}
+		t.add(current, ctx.Name, ctx.URL, ctx.Username, credentials)
+	}
+
+	return c.print(cfg.Contexts, t)
+}
+
+func (c *cli) contextsUse(args []string) error {
+
+	positional, err := c.parse(args, 1, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Context(positional[0]) == nil {
+		return fmt.Errorf("Context %s not found in %s", positional[0], c.configFile())
+	}
+
+	cfg.CurrentContext = positional[0]
+
+	return c.saveConfig(cfg)
+}
+
+func (c *cli) login(args []string) error {
+
+	var update ccp.Context
+	var passwordStdin bool
+
+	_, err := c.parse(args, 0, 0, func(fs *flag.FlagSet) {
+		fs.StringVar(&update.Name, "context", c.contextName, "name of the context")
+		fs.StringVar(&update.URL, "url", "", "base URL of CCP, e.g. https://ccp.example.com")
+		fs.StringVar(&update.Username, "username", "", "username")
+		fs.StringVar(&update.Credentials, "credentials", "", "where the password is read from: keyring, env or file:PATH")
+		fs.BoolVar(&passwordStdin, "password-stdin", false, "read the password from the first line of stdin")
+		fs.BoolVar(&update.Insecure, "insecure", false, "skip verifying the certificate of CCP, saved in the context")
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if update.Name == "" {
+		update.Name = cfg.CurrentContext
+	}
+	if update.Name == "" {
+		update.Name = "default"
+	}
+
+	ctx := cfg.Context(update.Name)
+	if ctx == nil {
+		cfg.SetContext(ccp.Context{Name: update.Name, Credentials: credentialsKeyring})
+		ctx = cfg.Context(update.Name)
+	}
+	if update.URL != "" {
+		ctx.URL = strings.TrimRight(update.URL, "/")
+	}
+	if update.Username != "" {
+		ctx.Username = update.Username
+	}
+	if update.Credentials != "" {
+		ctx.Credentials = update.Credentials
+	}
+	if update.Insecure {
+		ctx.Insecure = true
+	}
+	if ctx.URL == "" {
+		return usageError("The URL of a new context must be given with --url")
+	}
+
+	useKeyring := ctx.Credentials == credentialsKeyring
+
+	if ctx.Username == "" && (passwordStdin || useKeyring) {
+		return usageError("The username of a new context must be given with --username")
+	}
+
+	var creds ccp.CredentialsProvider
+
+	switch {
+	case passwordStdin:
+		password, err := c.readPassword()
+		if err != nil {
+			return err
+		}
+		creds = ccp.StaticCredentials(ctx.Username, password)
+	case useKeyring && os.Getenv(ccp.EnvPassword) == "":
+		return usageError("Give the password with --password-stdin or CCP_PASSWORD to save it in the keyring")
+	case useKeyring:
+		creds = ccp.StaticCredentials(ctx.Username, os.Getenv(ccp.EnvPassword))
+	default:
+		if creds, err = ctx.CredentialsProvider(); err != nil {
+			return err
+		}
+	}
+
+	client, err := ctx.NewClient()
+	if err != nil {
+		return err
+	}
+
+	client.Token = ""
+	client.Credentials = creds
+
+	if err := client.Login(nil); err != nil {
+		return err
+	}
+
+	if useKeyring {
+		saved, err := creds.Credentials()
+		if err != nil {
+			return err
+		}
+		if err := keyring.Set(keyringService, keyringUser(ctx), saved.Password); err != nil {
+			return fmt.Errorf("Saving the password in the keyring: %v", err)
+		}
+	}
+
+	cfg.CurrentContext = ctx.Name
+
+	if err := c.saveConfig(cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Logged in to %s as context %s\n", ctx.URL, ctx.Name)
+
+	return nil
+}
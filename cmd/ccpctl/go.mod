@@ -0,0 +1,19 @@
+module github.com/conmurphy/ccp-clientlibrary-go/cmd/ccpctl
+
+go 1.20
+
+require (
+	github.com/conmurphy/ccp-clientlibrary-go v0.0.0-00010101000000-000000000000
+	github.com/zalando/go-keyring v0.2.3
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/alessio/shellescape v1.4.1 // indirect
+	github.com/danieljoos/wincred v1.2.0 // indirect
+	github.com/godbus/dbus/v5 v5.1.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	gopkg.in/validator.v2 v2.0.0-20191107172027-c3144fdedc21 // indirect
+)
+
+replace github.com/conmurphy/ccp-clientlibrary-go => ../..
@@ -0,0 +1,247 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Command ccpctl manages Cisco Container Platform from the command line.
+//
+//	ccpctl login --context dev --url https://ccp-dev.example.com --username admin --password-stdin
+//	ccpctl clusters list -o yaml
+//	ccpctl clusters create -f newCluster.yaml
+//	ccpctl clusters wait myCluster --state READY --timeout 30m
+//	ccpctl kubeconfig myCluster > ~/.kube/config
+//
+// Control planes are kept as named contexts in ~/.ccp/config.yaml and passwords in the system keyring.
+// Run ccpctl help for every command
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+// cli holds the global options and streams of a single run of ccpctl
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath  string
+	contextName string
+	output      string
+	dryRun      bool
+
+	// command is the command being run
+	command *command
+	client  *ccp.Client
+}
+
+// command is a ccpctl command, either running a function or holding subcommands
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(c *cli, args []string) error
+	sub   []*command
+}
+
+var commands []*command
+
+func init() {
+	// Assigned here as the help command refers back to the list
+	commands = []*command{
+		loginCommand,
+		contextsCommand,
+		clustersCommand,
+		kubeconfigCommand,
+		usersCommand,
+		providersCommand,
+		aciProfilesCommand,
+		ldapCommand,
+		healthCommand,
+		{name: "help", usage: "help [COMMAND]", help: "Show help for a command", run: (*cli).help},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes ccpctl with the given arguments and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	fs := flag.NewFlagSet("ccpctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.configPath, "config", "", "config file, default $CCP_CONFIG or ~/.ccp/config.yaml")
+	fs.StringVar(&c.contextName, "context", "", "context to use, default $CCP_CONTEXT or the current context")
+	fs.StringVar(&c.output, "o", "table", "output format: table, json or yaml")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "print the requests which would change CCP without sending them")
+	fs.Usage = func() { c.usage(stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		c.usage(stderr)
+		return 2
+	}
+
+	if err := c.dispatch(commands, fs.Args(), ""); err != nil {
+		if planned, ok := ccp.DryRunRequest(err); ok {
+			fmt.Fprintln(stdout, planned)
+			return 0
+		}
+		fmt.Fprintln(stderr, "Error:", err)
+		if _, ok := err.(usageError); ok {
+			return 2
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// usageError is returned for a command line which cannot be run
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func (c *cli) dispatch(cmds []*command, args []string, parent string) error {
+
+	if len(args) == 0 {
+		return usageError(fmt.Sprintf("%s needs a subcommand, see ccpctl help %s", parent, parent))
+	}
+
+	for _, cmd := range cmds {
+		if cmd.name != args[0] {
+			continue
+		}
+		if cmd.run != nil {
+			c.command = cmd
+			return cmd.run(c, args[1:])
+		}
+		return c.dispatch(cmd.sub, args[1:], strings.TrimSpace(parent+" "+cmd.name))
+	}
+
+	return usageError(fmt.Sprintf("Unknown command %q, see ccpctl help", strings.TrimSpace(parent+" "+args[0])))
+}
+
+func (c *cli) usage(w io.Writer) {
+
+	fmt.Fprintln(w, "Usage: ccpctl [--config FILE] [--context NAME] [-o table|json|yaml] [--dry-run] COMMAND")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	lines := []string{}
+	var walk func(cmds []*command)
+	walk = func(cmds []*command) {
+		for _, cmd := range cmds {
+			if cmd.run != nil {
+				lines = append(lines, fmt.Sprintf("  %s\n      %s", cmd.usage, cmd.help))
+			}
+			walk(cmd.sub)
+		}
+	}
+	walk(commands)
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func (c *cli) help(args []string) error {
+
+	if len(args) == 0 {
+		c.usage(c.stdout)
+		return nil
+	}
+
+	cmds := commands
+	var found *command
+
+	for _, name := range args {
+		found = nil
+		for _, cmd := range cmds {
+			if cmd.name == name {
+				found = cmd
+			}
+		}
+		if found == nil {
+			return usageError(fmt.Sprintf("Unknown command %q", strings.Join(args, " ")))
+		}
+		cmds = found.sub
+	}
+
+	if found.run != nil {
+		fmt.Fprintf(c.stdout, "Usage: ccpctl %s\n\n%s\n", found.usage, found.help)
+		return nil
+	}
+
+	for _, cmd := range found.sub {
+		fmt.Fprintf(c.stdout, "  %s\n      %s\n", cmd.usage, cmd.help)
+	}
+
+	return nil
+}
+
+// parse parses the flags of a command, which may be given before or after its arguments, and checks
+// the number of arguments is between min and max
+func (c *cli) parse(args []string, min, max int, flags func(fs *flag.FlagSet)) ([]string, error) {
+
+	usage := c.command.usage
+
+	fs := flag.NewFlagSet(c.command.name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&c.output, "o", c.output, "output format: table, json or yaml")
+	if flags != nil {
+		flags(fs)
+	}
+
+	positional := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, usageError("Usage: ccpctl " + usage)
+			}
+			return nil, usageError(fmt.Sprintf("%v, usage: ccpctl %s", err, usage))
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) < min || len(positional) > max {
+		return nil, usageError("Usage: ccpctl " + usage)
+	}
+
+	return positional, nil
+}
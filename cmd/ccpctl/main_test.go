@@ -0,0 +1,217 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	"github.com/conmurphy/ccp-clientlibrary-go/ccptest"
+	keyring "github.com/zalando/go-keyring"
+)
+
+type testEnv struct {
+	t   *testing.T
+	srv *ccptest.Server
+	dir string
+	// saved holds the environment variables to restore on close
+	saved map[string]*string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+
+	keyring.MockInit()
+
+	dir, err := ioutil.TempDir("", "ccpctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &testEnv{
+		t:     t,
+		srv:   ccptest.NewServer(),
+		dir:   dir,
+		saved: map[string]*string{},
+	}
+
+	for _, name := range []string{ccp.EnvConfig, ccp.EnvContext, ccp.EnvURL, ccp.EnvToken, ccp.EnvUsername, ccp.EnvPassword} {
+		e.setenv(name, "")
+	}
+	e.setenv(ccp.EnvConfig, filepath.Join(dir, "config.yaml"))
+
+	return e
+}
+
+func (e *testEnv) setenv(name, value string) {
+
+	if _, ok := e.saved[name]; !ok {
+		if old, ok := os.LookupEnv(name); ok {
+			e.saved[name] = &old
+		} else {
+			e.saved[name] = nil
+		}
+	}
+
+	if value == "" {
+		os.Unsetenv(name)
+	} else {
+		os.Setenv(name, value)
+	}
+}
+
+func (e *testEnv) close() {
+
+	for name, value := range e.saved {
+		if value == nil {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, *value)
+		}
+	}
+
+	e.srv.Close()
+	os.RemoveAll(e.dir)
+}
+
+// run runs ccpctl and fails the test unless it exits with code
+func (e *testEnv) run(code int, stdin string, args ...string) string {
+
+	var stdout, stderr bytes.Buffer
+
+	got := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	if got != code {
+		e.t.Fatalf("ccpctl %s exited with %d, want %d\n%s%s", strings.Join(args, " "), got, code, stdout.String(), stderr.String())
+	}
+
+	return stdout.String()
+}
+
+func TestLoginAndClusters(t *testing.T) {
+
+	e := newTestEnv(t)
+	defer e.close()
+
+	e.run(0, ccptest.DefaultPassword+"\n", "login", "--context", "dev", "--url", e.srv.URL, "--username", ccptest.DefaultUsername, "--password-stdin")
+
+	// The password is now read from the keyring
+	out := e.run(0, "", "contexts", "list")
+	if !strings.Contains(out, "*") || !strings.Contains(out, e.srv.URL) {
+		t.Errorf("contexts list does not show the current context:\n%s", out)
+	}
+
+	providers := e.srv.NewClient()
+	if err := providers.Login(nil); err != nil {
+		t.Fatal(err)
+	}
+	configs, err := providers.GetProviderClientConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := filepath.Join(e.dir, "demo.yaml")
+	err = ioutil.WriteFile(spec, []byte(`name: demo
+provider_client_config_uuid: `+*configs[0].UUID+`
+kubernetes_version: 1.10.1
+datacenter: dc1
+cluster: cluster1
+resource_pool: cluster1/Resources
+datastore: datastore1
+networks: [net]
+ssh_user: ccp
+ssh_key: ssh-rsa AAAA
+workers: 1
+masters: 1
+vcpus: 2
+memory: 16384
+type: 1
+is_harbor_enabled: false
+is_istio_enabled: false
+network_plugin: {name: contiv-vpp, status: "", details: "{}"}
+deployer:
+  provider_type: vsphere
+  provider:
+    vsphere_datacenter: dc1
+    vsphere_datastore: datastore1
+    vsphere_client_config_uuid: `+*configs[0].UUID+`
+    vsphere_working_dir: /dc1/vm
+worker_node_pool: {vcpus: 2, memory: 16384, template: t}
+master_node_pool: {vcpus: 2, memory: 8192, template: t}
+infra: {datacenter: dc1, datastore: datastore1, cluster: cluster1, networks: [net], resource_pool: cluster1/Resources}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run prints the request and creates nothing
+	out = e.run(0, "", "--dry-run", "clusters", "create", "-f", spec)
+	if !strings.HasPrefix(out, "POST ") || len(e.srv.Clusters()) != 0 {
+		t.Fatalf("dry run create printed %q and left %d clusters", out, len(e.srv.Clusters()))
+	}
+
+	e.run(0, "", "clusters", "create", "-f", spec)
+	e.run(0, "", "clusters", "wait", "demo", "--interval", "10ms", "--timeout", "5s")
+	e.run(0, "", "clusters", "scale", "demo", "--workers", "3")
+
+	var clusters []ccp.Cluster
+	if err := json.Unmarshal([]byte(e.run(0, "", "clusters", "list", "-o", "json")), &clusters); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || *clusters[0].Workers != 3 {
+		t.Fatalf("clusters list returned %+v, want demo with 3 workers", clusters)
+	}
+
+	out = e.run(0, "", "clusters", "get", "demo", "-o", "yaml")
+	if !strings.Contains(out, "name: demo") {
+		t.Errorf("clusters get -o yaml printed:\n%s", out)
+	}
+
+	if out := e.run(0, "", "kubeconfig", "demo"); !strings.Contains(out, "apiVersion") {
+		t.Errorf("kubeconfig printed:\n%s", out)
+	}
+
+	e.run(1, "n\n", "clusters", "delete", "demo")
+	e.run(0, "", "clusters", "delete", "demo", "--yes")
+	e.run(0, "", "clusters", "wait", "demo", "--state", "deleted", "--interval", "10ms", "--timeout", "5s")
+}
+
+func TestUsersWithEnvironmentCredentials(t *testing.T) {
+
+	e := newTestEnv(t)
+	defer e.close()
+
+	e.setenv(ccp.EnvURL, e.srv.URL)
+	e.setenv(ccp.EnvUsername, ccptest.DefaultUsername)
+	e.setenv(ccp.EnvPassword, ccptest.DefaultPassword)
+
+	e.run(0, "Secret123!\n", "users", "add", "jdoe", "--role", "Developer", "--first-name", "Jane", "--password-stdin")
+	e.run(0, "", "users", "patch", "jdoe", "--last-name", "Doe", "--disable")
+
+	out := e.run(0, "", "users", "list")
+	if !strings.Contains(out, "jdoe") || !strings.Contains(out, "Doe") {
+		t.Errorf("users list printed:\n%s", out)
+	}
+
+	e.run(0, "", "users", "delete", "jdoe")
+	e.run(2, "", "users", "add", "nopassword", "--role", "Developer")
+	e.run(2, "", "clusters", "frobnicate")
+}
@@ -0,0 +1,138 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// table is the rows printed for the table output format
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+// print writes v in the chosen output format, using t for the table format. Sensitive values, such
+// as passwords and private keys, are redacted in every format
+func (c *cli) print(v interface{}, t *table) error {
+
+	switch c.output {
+	case "json", "yaml":
+		j, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		j = ccp.RedactJSON(j)
+
+		if c.output == "json" {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, j, "", "  "); err != nil {
+				return err
+			}
+			fmt.Fprintln(c.stdout, indented.String())
+			return nil
+		}
+
+		y, err := toYAML(j)
+		if err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(y)
+		return err
+
+	case "table", "":
+		w := tabwriter.NewWriter(c.stdout, 0, 4, 3, ' ', 0)
+		fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+
+	return usageError(fmt.Sprintf("Unknown output format %q, use table, json or yaml", c.output))
+}
+
+// toYAML converts a JSON document to block style YAML, keeping the order of its fields
+func toYAML(j []byte) ([]byte, error) {
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(j, &node); err != nil {
+		return nil, err
+	}
+
+	var clear func(n *yaml.Node)
+	clear = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			clear(child)
+		}
+	}
+	clear(&node)
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func str(s *string) string {
+
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func num(i *int64) string {
+
+	if i == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*i, 10)
+}
+
+func boolean(b *bool) string {
+
+	if b == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,238 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+var providersCommand = &command{
+	name: "providers",
+	sub: []*command{
+		{name: "list", usage: "providers list", help: "List provider client configs", run: (*cli).providersList},
+		{name: "browse", usage: "providers browse UUID [DATACENTER [CLUSTER]]", help: "List the vSphere datacenters of a provider, the clusters, networks, datastores and VMs of a datacenter, or the resource pools of a cluster", run: (*cli).providersBrowse},
+	},
+}
+
+var aciProfilesCommand = &command{
+	name:  "aci-profiles",
+	usage: "aci-profiles",
+	help:  "List ACI profiles",
+	run:   (*cli).aciProfiles,
+}
+
+var ldapCommand = &command{
+	name:  "ldap",
+	usage: "ldap",
+	help:  "Show the LDAP setup",
+	run:   (*cli).ldap,
+}
+
+var healthCommand = &command{
+	name:  "health",
+	usage: "health",
+	help:  "Show the health of the CCP control plane",
+	run:   (*cli).health,
+}
+
+func (c *cli) providersList(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	providers, err := client.GetProviderClientConfigs()
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"NAME", "TYPE", "ADDRESS", "USERNAME", "UUID"}}
+
+	for _, p := range providers {
+		address, username := "", ""
+		if p.Config != nil {
+			address, username = str(p.Config.IP), str(p.Config.Username)
+			if p.Config.Port != nil {
+				address += ":" + num(p.Config.Port)
+			}
+		}
+		t.add(str(p.Name), num(p.Type), address, username, str(p.UUID))
+	}
+
+	return c.print(providers, t)
+}
+
+func (c *cli) providersBrowse(args []string) error {
+
+	positional, err := c.parse(args, 1, 3, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	uuid := positional[0]
+	result := &ccp.Vsphere{}
+
+	switch len(positional) {
+	case 1:
+		result, err = client.GetProviderClientConfigVsphereDatacenter(uuid)
+	case 2:
+		datacenter := positional[1]
+		lookups := []struct {
+			get  func(string, string) (*ccp.Vsphere, error)
+			copy func(v *ccp.Vsphere)
+		}{
+			{client.GetProviderClientConfigVsphereDatacenterClusters, func(v *ccp.Vsphere) { result.Clusters = v.Clusters }},
+			{client.GetProviderClientConfigVsphereDatacenterNetworks, func(v *ccp.Vsphere) { result.Networks = v.Networks }},
+			{client.GetProviderClientConfigVsphereDatacenterDatastores, func(v *ccp.Vsphere) { result.Datastores = v.Datastores }},
+			{client.GetProviderClientConfigVsphereDatacenterVMs, func(v *ccp.Vsphere) { result.VMs = v.VMs }},
+		}
+		for _, lookup := range lookups {
+			v, err := lookup.get(uuid, datacenter)
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				lookup.copy(v)
+			}
+		}
+	case 3:
+		result, err = client.GetProviderClientConfigVsphereDatacenterClusterPools(uuid, positional[1], positional[2])
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		result = &ccp.Vsphere{}
+	}
+
+	t := &table{header: []string{"KIND", "NAME"}}
+
+	for _, list := range []struct {
+		kind  string
+		names *[]string
+	}{
+		{"datacenter", result.Datacenters},
+		{"cluster", result.Clusters},
+		{"network", result.Networks},
+		{"datastore", result.Datastores},
+		{"vm", result.VMs},
+		{"pool", result.Pools},
+	} {
+		if list.names == nil {
+			continue
+		}
+		for _, name := range *list.names {
+			t.add(list.kind, name)
+		}
+	}
+
+	return c.print(result, t)
+}
+
+func (c *cli) aciProfiles(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	profiles, err := client.GetACIProfiles()
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"NAME", "APIC HOSTS", "VMM DOMAIN", "VRF", "UUID"}}
+
+	for _, p := range profiles {
+		t.add(str(p.Name), str(p.APICHosts), str(p.ACIVMMDomainName), str(p.VRFName), str(p.UUID))
+	}
+
+	return c.print(profiles, t)
+}
+
+func (c *cli) ldap(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	setup, err := client.GetLDAPSetup()
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"SERVER", "PORT", "BASE DN", "SERVICE ACCOUNT", "STARTTLS"}}
+	t.add(str(setup.Server), num(setup.Port), str(setup.BaseDN), str(setup.ServiceAccountDN), boolean(setup.StartTLS))
+
+	return c.print(setup, t)
+}
+
+func (c *cli) health(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	health, err := client.GetHealth()
+	if err != nil {
+		return err
+	}
+
+	if c.output == "table" || c.output == "" {
+		fmt.Fprintf(c.stdout, "System health: %s, %s of %s nodes\n\n", str(health.TotalSystemHealth), num(health.CurrentNodes), num(health.ExpectedNodes))
+	}
+
+	t := &table{header: []string{"KIND", "NAME", "CONDITION", "STATUS", "SINCE"}}
+
+	if health.NodesStatus != nil {
+		for _, n := range *health.NodesStatus {
+			t.add("node", str(n.NodeName), str(n.NodeCondition), str(n.NodeStatus), str(n.LastTransitionTime))
+		}
+	}
+	if health.PodStatusList != nil {
+		for _, p := range *health.PodStatusList {
+			t.add("pod", str(p.PodName), str(p.PodCondition), str(p.PodStatus), str(p.LastTransitionTime))
+		}
+	}
+
+	return c.print(health, t)
+}
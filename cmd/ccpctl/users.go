@@ -0,0 +1,226 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+var usersCommand = &command{
+	name: "users",
+	sub: []*command{
+		{name: "list", usage: "users list", help: "List local users", run: (*cli).usersList},
+		{name: "add", usage: "users add USERNAME --role ROLE [--first-name NAME] [--last-name NAME] --password-stdin", help: "Add a local user", run: (*cli).usersAdd},
+		{name: "patch", usage: "users patch USERNAME [--role ROLE] [--first-name NAME] [--last-name NAME] [--disable|--enable] [--password-stdin]", help: "Change a local user", run: (*cli).usersPatch},
+		{name: "delete", usage: "users delete USERNAME", help: "Delete a local user", run: (*cli).usersDelete},
+	},
+}
+
+func userTable(users ...ccp.User) *table {
+
+	t := &table{header: []string{"USERNAME", "ROLE", "FIRST NAME", "LAST NAME", "DISABLED"}}
+
+	for _, user := range users {
+		t.add(str(user.Username), str(user.Role), str(user.FirstName), str(user.LastName), boolean(user.Disable))
+	}
+
+	return t
+}
+
+// userFlags are the flags of users add and users patch. Flags which are not given are left nil
+type userFlags struct {
+	role, firstName, lastName optionalString
+	disable, enable           bool
+	passwordStdin             bool
+}
+
+// optionalString is a string flag which records whether it was given
+type optionalString struct {
+	value *string
+}
+
+func (s *optionalString) String() string {
+	return str(s.value)
+}
+
+func (s *optionalString) Set(v string) error {
+	s.value = &v
+	return nil
+}
+
+func (f *userFlags) register(fs *flag.FlagSet, patch bool) {
+
+	fs.Var(&f.role, "role", "role of the user, e.g. SysAdmin or Developer")
+	fs.Var(&f.firstName, "first-name", "first name")
+	fs.Var(&f.lastName, "last-name", "last name")
+	fs.BoolVar(&f.passwordStdin, "password-stdin", false, "read the password from the first line of stdin")
+	if patch {
+		fs.BoolVar(&f.disable, "disable", false, "disable the user")
+		fs.BoolVar(&f.enable, "enable", false, "enable the user")
+	}
+}
+
+// user returns the user described by the flags
+func (c *cli) user(username string, f *userFlags) (*ccp.User, error) {
+
+	user := &ccp.User{
+		Username:  ccp.String(username),
+		Role:      f.role.value,
+		FirstName: f.firstName.value,
+		LastName:  f.lastName.value,
+	}
+
+	if f.disable && f.enable {
+		return nil, usageError("Only one of --disable and --enable can be given")
+	}
+	if f.disable || f.enable {
+		user.Disable = ccp.Bool(f.disable)
+	}
+
+	if f.passwordStdin {
+		password, err := c.readPassword()
+		if err != nil {
+			return nil, err
+		}
+		user.Password = ccp.String(password)
+	}
+
+	return user, nil
+}
+
+// readPassword reads a password from the first line of stdin
+func (c *cli) readPassword() (string, error) {
+
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+
+	if password == "" {
+		if err != nil {
+			return "", fmt.Errorf("Reading the password from stdin: %v", err)
+		}
+		return "", errors.New("Password read from stdin is empty")
+	}
+
+	return password, nil
+}
+
+func (c *cli) usersList(args []string) error {
+
+	if _, err := c.parse(args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	users, err := client.GetUsers()
+	if err != nil {
+		return err
+	}
+
+	return c.print(users, userTable(users...))
+}
+
+func (c *cli) usersAdd(args []string) error {
+
+	var f userFlags
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		f.register(fs, false)
+	})
+	if err != nil {
+		return err
+	}
+	if !f.passwordStdin {
+		return usageError("The password of a new user must be given with --password-stdin")
+	}
+
+	user, err := c.user(positional[0], &f)
+	if err != nil {
+		return err
+	}
+	user.Disable = ccp.Bool(false)
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	added, err := client.AddUser(user)
+	if err != nil {
+		return err
+	}
+
+	return c.print(added, userTable(*added))
+}
+
+func (c *cli) usersPatch(args []string) error {
+
+	var f userFlags
+
+	positional, err := c.parse(args, 1, 1, func(fs *flag.FlagSet) {
+		f.register(fs, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	user, err := c.user(positional[0], &f)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	patched, err := client.PatchUser(user)
+	if err != nil {
+		return err
+	}
+
+	return c.print(patched, userTable(*patched))
+}
+
+func (c *cli) usersDelete(args []string) error {
+
+	positional, err := c.parse(args, 1, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteUser(positional[0]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "User %s deleted\n", positional[0])
+
+	return nil
+}
@@ -0,0 +1,8 @@
+module github.com/conmurphy/ccp-clientlibrary-go
+
+go 1.20
+
+require (
+	gopkg.in/validator.v2 v2.0.0-20191107172027-c3144fdedc21
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,356 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Command mockgen generates the ccpmock package from the service interfaces in the ccp package. It is
+// run by go generate ./ccpmock
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ccpImport = "github.com/conmurphy/ccp-clientlibrary-go/ccp"
+
+type method struct {
+	name    string
+	service string
+	params  []param
+	results []string
+}
+
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+func main() {
+
+	src := flag.String("src", "../ccp", "directory of the ccp package")
+	root := flag.String("interface", "API", "interface to mock")
+	out := flag.String("out", "mock.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, *src, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pkg, ok := pkgs["ccp"]
+	if !ok {
+		log.Fatalf("package ccp not found in %s", *src)
+	}
+
+	interfaces := map[string]*ast.InterfaceType{}
+	imports := map[string]string{}
+
+	for _, file := range pkg.Files {
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := filepath.Base(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = path
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if it, ok := ts.Type.(*ast.InterfaceType); ok {
+					interfaces[ts.Name.Name] = it
+				}
+			}
+		}
+	}
+
+	g := &generator{fset: fset, interfaces: interfaces, imports: imports, used: map[string]bool{}}
+	g.collect(*root, *root)
+
+	code, err := g.render(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	fset       *token.FileSet
+	interfaces map[string]*ast.InterfaceType
+	imports    map[string]string
+	used       map[string]bool
+	methods    []method
+}
+
+// collect adds the methods of the named interface, following embedded interfaces
+func (g *generator) collect(name, service string) {
+
+	it, ok := g.interfaces[name]
+	if !ok {
+		log.Fatalf("interface %s not found", name)
+	}
+
+	for _, field := range it.Methods.List {
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			g.collect(t.Name, t.Name)
+		case *ast.FuncType:
+			m := method{name: field.Names[0].Name, service: service}
+			for i, p := range fieldList(t.Params) {
+				_, variadic := p.Type.(*ast.Ellipsis)
+				typ := p.Type
+				if variadic {
+					typ = p.Type.(*ast.Ellipsis).Elt
+				}
+				names := p.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", i))}
+				}
+				for _, n := range names {
+					m.params = append(m.params, param{name: n.Name, typ: g.typeString(typ), variadic: variadic})
+				}
+			}
+			for _, r := range fieldList(t.Results) {
+				count := len(r.Names)
+				if count == 0 {
+					count = 1
+				}
+				for i := 0; i < count; i++ {
+					m.results = append(m.results, g.typeString(r.Type))
+				}
+			}
+			g.methods = append(g.methods, m)
+		}
+	}
+}
+
+func fieldList(fl *ast.FieldList) []*ast.Field {
+
+	if fl == nil {
+		return nil
+	}
+
+	return fl.List
+}
+
+// typeString prints a type expression, qualifying identifiers declared in the ccp package
+func (g *generator) typeString(expr ast.Expr) string {
+
+	expr = g.qualify(expr)
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, expr)
+
+	return buf.String()
+}
+
+func (g *generator) qualify(expr ast.Expr) ast.Expr {
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			g.used["ccp"] = true
+			return &ast.SelectorExpr{X: ast.NewIdent("ccp"), Sel: ast.NewIdent(t.Name)}
+		}
+		return ast.NewIdent(t.Name)
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		g.used[pkg] = true
+		return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(t.Sel.Name)}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.qualify(t.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: g.qualify(t.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.qualify(t.Key), Value: g.qualify(t.Value)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: t.Dir, Value: g.qualify(t.Value)}
+	case *ast.FuncType:
+		params := &ast.FieldList{}
+		for _, f := range fieldList(t.Params) {
+			params.List = append(params.List, &ast.Field{Names: f.Names, Type: g.qualify(f.Type)})
+		}
+		var results *ast.FieldList
+		if t.Results != nil {
+			results = &ast.FieldList{}
+			for _, f := range t.Results.List {
+				results.List = append(results.List, &ast.Field{Names: f.Names, Type: g.qualify(f.Type)})
+			}
+		}
+		return &ast.FuncType{Params: params, Results: results}
+	}
+
+	return expr
+}
+
+func (g *generator) render(root string) ([]byte, error) {
+
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by internal/mockgen from the ccp service interfaces. DO NOT EDIT.\n\n")
+	b.WriteString("package ccpmock\n\n")
+
+	std := []string{`"sync"`}
+	other := []string{}
+	for name := range g.used {
+		path := ccpImport
+		if name != "ccp" {
+			path = g.imports[name]
+		}
+		spec := strconv.Quote(path)
+		if filepath.Base(path) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	b.WriteString("import (\n")
+	for _, p := range std {
+		b.WriteString(p + "\n")
+	}
+	if len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range other {
+		b.WriteString(p + "\n")
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "var _ ccp.%s = (*Client)(nil)\n\n", root)
+
+	b.WriteString("// Client is a mock of every ccp service interface. Each method records the call and then calls the\n")
+	b.WriteString("// function in the field of the same name with a Func suffix. Methods whose function is not set return\n")
+	b.WriteString("// zero values and an ErrNotStubbed error\n")
+	b.WriteString("type Client struct {\n")
+	service := ""
+	for _, m := range g.methods {
+		if m.service != service {
+			if service != "" {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "// ccp.%s\n", m.service)
+			service = m.service
+		}
+		fmt.Fprintf(&b, "%sFunc func(%s) %s\n", m.name, m.signatureParams(), m.signatureResults())
+	}
+	b.WriteString("\nmu sync.Mutex\ncalls []Call\n}\n\n")
+
+	for _, m := range g.methods {
+		args := []string{}
+		callArgs := []string{}
+		for _, p := range m.params {
+			args = append(args, p.name)
+			if p.variadic {
+				callArgs = append(callArgs, p.name+"...")
+			} else {
+				callArgs = append(callArgs, p.name)
+			}
+		}
+
+		fmt.Fprintf(&b, "func (m *Client) %s(%s) %s {\n", m.name, m.signatureParams(), m.signatureResults())
+		fmt.Fprintf(&b, "m.record(%q%s)\n", m.name, prefixJoin(args))
+		fmt.Fprintf(&b, "if m.%sFunc == nil {\n", m.name)
+
+		zeros := []string{}
+		for i, r := range m.results {
+			if r == "error" {
+				zeros = append(zeros, fmt.Sprintf("notStubbed(%q)", m.name))
+				continue
+			}
+			fmt.Fprintf(&b, "var r%d %s\n", i, r)
+			zeros = append(zeros, fmt.Sprintf("r%d", i))
+		}
+		if len(zeros) > 0 {
+			fmt.Fprintf(&b, "return %s\n", strings.Join(zeros, ", "))
+		} else {
+			b.WriteString("return\n")
+		}
+		b.WriteString("}\n")
+
+		if len(m.results) > 0 {
+			fmt.Fprintf(&b, "return m.%sFunc(%s)\n", m.name, strings.Join(callArgs, ", "))
+		} else {
+			fmt.Fprintf(&b, "m.%sFunc(%s)\n", m.name, strings.Join(callArgs, ", "))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+func (m method) signatureParams() string {
+
+	parts := []string{}
+	for _, p := range m.params {
+		if p.variadic {
+			parts = append(parts, p.name+" ..."+p.typ)
+		} else {
+			parts = append(parts, p.name+" "+p.typ)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (m method) signatureResults() string {
+
+	switch len(m.results) {
+	case 0:
+		return ""
+	case 1:
+		return m.results[0]
+	}
+
+	return "(" + strings.Join(m.results, ", ") + ")"
+}
+
+func prefixJoin(args []string) string {
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(args, ", ")
+}
@@ -0,0 +1,63 @@
+/*Copyright (c) 2019 Cisco and/or its affiliates.
+
+This software is licensed to you under the terms of the Cisco Sample
+Code License, Version 1.0 (the "License"). You may obtain a copy of the
+License at
+
+               https://developer.cisco.com/docs/licenses
+
+All use of the material herein must be in accordance with the terms of
+the License. All rights not expressly granted by the License are
+reserved. Unless required by applicable law or agreed to separately in
+writing, software distributed under the License is distributed on an "AS
+IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+or implied.*/
+
+// Command schemagen writes the JSON Schema of each ccp model, e.g. cluster.schema.json, and the OpenAPI
+// document of the endpoints covered by the client, openapi.json. It is run by go generate ./ccp
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conmurphy/ccp-clientlibrary-go/ccp"
+)
+
+func main() {
+
+	out := flag.String("out", "schema", "output directory")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	schemas, err := ccp.Schemas()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for name, schema := range schemas {
+		write(filepath.Join(*out, strings.ToLower(name)+".schema.json"), schema)
+	}
+
+	write(filepath.Join(*out, "openapi.json"), ccp.OpenAPI())
+}
+
+func write(path string, v interface{}) {
+
+	j, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, append(j, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}